@@ -4,6 +4,7 @@
 package main
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
@@ -14,9 +15,12 @@ import (
 	"github.com/docker/docker/client"
 	"github.com/rs/zerolog"
 
+	"github.com/sudosu404/tailnet-lib/internal/accesslog"
 	"github.com/sudosu404/tailnet-lib/internal/config"
 	"github.com/sudosu404/tailnet-lib/internal/core"
 	"github.com/sudosu404/tailnet-lib/internal/dashboard"
+	"github.com/sudosu404/tailnet-lib/internal/identity"
+	"github.com/sudosu404/tailnet-lib/internal/metrics"
 	pm "github.com/sudosu404/tailnet-lib/internal/proxymanager"
 )
 
@@ -27,6 +31,8 @@ type WebApp struct {
 	Docker       *client.Client
 	ProxyManager *pm.ProxyManager
 	Dashboard    *dashboard.Dashboard
+
+	shutdownTracing core.TracingShutdown
 }
 
 func InitializeApp() (*WebApp, error) {
@@ -36,6 +42,13 @@ func InitializeApp() (*WebApp, error) {
 	}
 	logger := core.NewLog()
 
+	tracingShutdown, err := core.NewTracing(logger)
+	if err != nil {
+		return nil, err
+	}
+
+	identity.Init(logger)
+
 	httpServer := core.NewHTTPServer(logger)
 	httpServer.Use(core.SessionMiddleware)
 
@@ -50,11 +63,12 @@ func InitializeApp() (*WebApp, error) {
 	dash := dashboard.NewDashboard(httpServer, logger, proxymanager)
 
 	webApp := &WebApp{
-		Log:          logger,
-		HTTP:         httpServer,
-		Health:       health,
-		ProxyManager: proxymanager,
-		Dashboard:    dash,
+		Log:             logger,
+		HTTP:            httpServer,
+		Health:          health,
+		ProxyManager:    proxymanager,
+		Dashboard:       dash,
+		shutdownTracing: tracingShutdown,
 	}
 	return webApp, nil
 }
@@ -116,6 +130,8 @@ func (app *WebApp) Start() {
 	//
 	app.Dashboard.AddRoutes()
 	core.PprofAddRoutes(app.HTTP)
+	metrics.AddRoutes(app.HTTP)
+	identity.AddRoutes(app.HTTP)
 }
 
 func (app *WebApp) Stop() {
@@ -127,5 +143,13 @@ func (app *WebApp) Stop() {
 	//
 	app.ProxyManager.StopAllProxies()
 
+	if err := app.shutdownTracing(context.Background()); err != nil {
+		app.Log.Error().Err(err).Msg("error flushing traces")
+	}
+
+	if err := accesslog.Close(); err != nil {
+		app.Log.Error().Err(err).Msg("error closing access logs")
+	}
+
 	app.Log.Info().Msg("Server was shutdown successfully")
 }
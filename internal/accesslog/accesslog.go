@@ -0,0 +1,150 @@
+// SPDX-FileCopyrightText: 2025 Hector @sudosu404 <hector@email.gnx>
+// SPDX-License-Identifier: AGPL3
+
+// Package accesslog writes the access logs of the proxies in the Common, Combined
+// or JSON formats, to stdout, stderr or rotated files.
+package accesslog
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/sudosu404/tailnet-lib/internal/config"
+	"github.com/sudosu404/tailnet-lib/internal/model"
+)
+
+type (
+	// Logger struct writes the access log entries of a proxy.
+	Logger struct {
+		out    io.Writer
+		format string
+	}
+
+	contextKey string
+)
+
+const contextKeyEntry contextKey = "contextkey.accesslog.entry"
+
+var (
+	ErrInvalidFormat = errors.New("invalid access log format, expected common, combined or json")
+
+	// outputs are shared by the proxies writing to the same file.
+	outputs = make(map[string]io.Writer)
+	mtx     sync.Mutex
+)
+
+// Open function returns the Logger of an access log configuration,
+// empty values use the access log configuration of tailnet.yaml.
+func Open(accessLog model.AccessLog) (*Logger, error) {
+	if config.Config != nil {
+		accessLog = accessLog.Merge(config.Config.AccessLog)
+	}
+
+	format := accessLog.GetFormat()
+	switch format {
+	case model.AccessLogCommon, model.AccessLogCombined, model.AccessLogJSON:
+	default:
+		return nil, ErrInvalidFormat
+	}
+
+	out, err := openOutput(accessLog.GetOutput(), accessLog.Rotation)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Logger{out: out, format: format}, nil
+}
+
+// openOutput function returns the writer of an output, files are opened once.
+// The rotation of a file is set by the first proxy that opens it.
+func openOutput(output string, rotation model.LogRotation) (io.Writer, error) {
+	switch output {
+	case model.AccessLogStdout:
+		return os.Stdout, nil
+	case model.AccessLogStderr:
+		return os.Stderr, nil
+	}
+
+	path, err := filepath.Abs(output)
+	if err != nil {
+		return nil, err
+	}
+
+	mtx.Lock()
+	defer mtx.Unlock()
+
+	if out, ok := outputs[path]; ok {
+		return out, nil
+	}
+
+	f, err := openRotatingFile(path, rotation)
+	if err != nil {
+		return nil, err
+	}
+
+	outputs[path] = f
+
+	return f, nil
+}
+
+// Close function closes the access log files.
+func Close() error {
+	mtx.Lock()
+	defer mtx.Unlock()
+
+	var errs error
+	for path, out := range outputs {
+		if c, ok := out.(io.Closer); ok {
+			errs = errors.Join(errs, c.Close())
+		}
+		delete(outputs, path)
+	}
+
+	return errs
+}
+
+// Log method writes an entry, a line for each entry.
+func (l *Logger) Log(e *Entry) error {
+	var line []byte
+
+	switch l.format {
+	case model.AccessLogCommon:
+		line = e.appendCommon(nil)
+	case model.AccessLogCombined:
+		line = e.appendCombined(nil)
+	default:
+		var err error
+		if line, err = e.marshalJSON(); err != nil {
+			return err
+		}
+	}
+
+	_, err := l.out.Write(append(line, '\n'))
+
+	return err
+}
+
+// EntryFromContext function returns the access log entry of the request being logged.
+func EntryFromContext(ctx context.Context) (*Entry, bool) {
+	e, ok := ctx.Value(contextKeyEntry).(*Entry)
+	return e, ok
+}
+
+// SetWhois function records the Tailscale identity of the request being logged.
+func SetWhois(ctx context.Context, who model.Whois) {
+	if e, ok := EntryFromContext(ctx); ok {
+		e.User = who.Username
+		e.Node = who.GetNodeName()
+	}
+}
+
+// SetUpstream function records the target of the request being logged.
+func SetUpstream(ctx context.Context, upstream string) {
+	if e, ok := EntryFromContext(ctx); ok {
+		e.Upstream = upstream
+	}
+}
@@ -0,0 +1,118 @@
+// SPDX-FileCopyrightText: 2025 Hector @sudosu404 <hector@email.gnx>
+// SPDX-License-Identifier: AGPL3
+
+package accesslog
+
+import (
+	"encoding/json"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type (
+	// Entry struct stores the fields of an access log entry.
+	Entry struct {
+		Time      time.Time     `json:"time"`
+		Proxy     string        `json:"proxy"`
+		Port      string        `json:"port"`
+		Client    string        `json:"client"`
+		User      string        `json:"user,omitempty"`
+		Node      string        `json:"node,omitempty"`
+		Method    string        `json:"method"`
+		Host      string        `json:"host"`
+		URI       string        `json:"uri"`
+		Proto     string        `json:"proto"`
+		Status    int           `json:"status"`
+		BytesIn   int64         `json:"bytesIn"`
+		BytesOut  int64         `json:"bytesOut"`
+		Referer   string        `json:"referer,omitempty"`
+		UserAgent string        `json:"userAgent,omitempty"`
+		Upstream  string        `json:"upstream,omitempty"`
+		Duration  time.Duration `json:"-"`
+	}
+
+	jsonEntry struct {
+		*Entry
+		// LatencyMs is the time to serve the request in milliseconds.
+		LatencyMs float64 `json:"latencyMs"`
+	}
+)
+
+const clfTimeFormat = "02/Jan/2006:15:04:05 -0700"
+
+// appendCommon method appends the entry in the Common Log Format, followed by
+// the target and the latency in milliseconds:
+//
+//	client - user [time] "request" status bytes "upstream" latency
+func (e *Entry) appendCommon(b []byte) []byte {
+	return e.appendExtra(e.appendCLF(b))
+}
+
+// appendCombined method appends the entry in the Combined Log Format, followed by
+// the target and the latency in milliseconds:
+//
+//	client - user [time] "request" status bytes "referer" "user agent" "upstream" latency
+func (e *Entry) appendCombined(b []byte) []byte {
+	b = e.appendCLF(b)
+	b = append(b, ' ')
+	b = strconv.AppendQuote(b, orDash(e.Referer))
+	b = append(b, ' ')
+	b = strconv.AppendQuote(b, orDash(e.UserAgent))
+
+	return e.appendExtra(b)
+}
+
+func (e *Entry) appendCLF(b []byte) []byte {
+	b = append(b, clfField(clientHost(e.Client))...)
+	b = append(b, " - "...)
+	b = append(b, clfField(e.User)...)
+	b = append(b, " ["...)
+	b = e.Time.AppendFormat(b, clfTimeFormat)
+	b = append(b, "] "...)
+	b = strconv.AppendQuote(b, e.Method+" "+e.URI+" "+e.Proto)
+	b = append(b, ' ')
+	b = strconv.AppendInt(b, int64(e.Status), 10)
+	b = append(b, ' ')
+
+	if e.BytesOut > 0 {
+		return strconv.AppendInt(b, e.BytesOut, 10)
+	}
+	return append(b, '-')
+}
+
+func (e *Entry) appendExtra(b []byte) []byte {
+	b = append(b, ' ')
+	b = strconv.AppendQuote(b, orDash(e.Upstream))
+	b = append(b, ' ')
+
+	return strconv.AppendInt(b, e.Duration.Milliseconds(), 10)
+}
+
+func (e *Entry) marshalJSON() ([]byte, error) {
+	return json.Marshal(jsonEntry{
+		Entry:     e,
+		LatencyMs: float64(e.Duration.Microseconds()) / float64(time.Millisecond/time.Microsecond),
+	})
+}
+
+// clfField function returns a field without spaces, "-" if the field is empty.
+func clfField(s string) string {
+	return strings.ReplaceAll(orDash(s), " ", "_")
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// clientHost function returns the client address without the port.
+func clientHost(addr string) string {
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		return host
+	}
+	return addr
+}
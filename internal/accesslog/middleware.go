@@ -0,0 +1,115 @@
+// SPDX-FileCopyrightText: 2025 Hector @sudosu404 <hector@email.gnx>
+// SPDX-License-Identifier: AGPL3
+
+package accesslog
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+type (
+	// responseRecorder struct records the status and the size of a response.
+	responseRecorder struct {
+		http.ResponseWriter
+		status      int
+		bytes       int64
+		wroteHeader bool
+	}
+
+	// bodyCounter struct counts the bytes read from the request body.
+	bodyCounter struct {
+		io.ReadCloser
+		bytes int64
+	}
+)
+
+// Middleware method logs the requests of a proxy port. The identity and the target
+// are recorded by the inner handlers with SetWhois and SetUpstream.
+// Errors writing the log are sent to the application log.
+func (l *Logger) Middleware(proxyName, portName string, log zerolog.Logger, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		e := &Entry{
+			Time:      time.Now(),
+			Proxy:     proxyName,
+			Port:      portName,
+			Client:    r.RemoteAddr,
+			Method:    r.Method,
+			Host:      r.Host,
+			URI:       r.RequestURI,
+			Proto:     r.Proto,
+			Referer:   r.Referer(),
+			UserAgent: r.UserAgent(),
+		}
+
+		rec := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+
+		var body *bodyCounter
+		if r.Body != nil && r.Body != http.NoBody {
+			body = &bodyCounter{ReadCloser: r.Body}
+			r.Body = body
+		}
+
+		next.ServeHTTP(rec, r.WithContext(context.WithValue(r.Context(), contextKeyEntry, e)))
+
+		e.Status = rec.status
+		e.BytesOut = rec.bytes
+		e.Duration = time.Since(e.Time)
+		if body != nil {
+			e.BytesIn = body.bytes
+		}
+
+		if err := l.Log(e); err != nil {
+			log.Error().Err(err).Msg("error writing access log")
+		}
+	})
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	// informational responses are followed by the final one
+	if status >= http.StatusOK && !r.wroteHeader {
+		r.wroteHeader = true
+		r.status = status
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+	n, err := r.ResponseWriter.Write(b)
+	r.bytes += int64(n)
+	return n, err
+}
+
+func (r *responseRecorder) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack method records upgraded connections, like websockets, as 101 responses.
+func (r *responseRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, rw, err := http.NewResponseController(r.ResponseWriter).Hijack()
+	if err == nil && !r.wroteHeader {
+		r.wroteHeader = true
+		r.status = http.StatusSwitchingProtocols
+	}
+	return conn, rw, err
+}
+
+// Unwrap method returns the original writer, used by http.ResponseController.
+func (r *responseRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+func (b *bodyCounter) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.bytes += int64(n)
+	return n, err
+}
@@ -0,0 +1,179 @@
+// SPDX-FileCopyrightText: 2025 Hector @sudosu404 <hector@email.gnx>
+// SPDX-License-Identifier: AGPL3
+
+package accesslog
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sudosu404/tailnet-lib/internal/consts"
+	"github.com/sudosu404/tailnet-lib/internal/model"
+)
+
+type (
+	// rotatingFile struct is a log file rotated by size and age.
+	// Rotated files are renamed with the rotation time, like access-20250102T150405.000.log.
+	rotatingFile struct {
+		file     *os.File
+		opened   time.Time
+		path     string
+		rotation model.LogRotation
+		size     int64
+		mtx      sync.Mutex
+	}
+)
+
+const (
+	backupTimeFormat = "20060102T150405.000"
+	// legacyBackupTimeFormat is the name of the files rotated by older versions.
+	legacyBackupTimeFormat = "20060102T150405"
+)
+
+// openRotatingFile function opens a log file for appending.
+func openRotatingFile(path string, rotation model.LogRotation) (*rotatingFile, error) {
+	f := &rotatingFile{
+		path:     path,
+		rotation: rotation,
+	}
+
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+
+	return f, nil
+}
+
+func (f *rotatingFile) open() error {
+	if err := os.MkdirAll(filepath.Dir(f.path), consts.PermOwnerAll); err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, consts.PermOwnerRead|consts.PermOwnerWrite)
+	if err != nil {
+		return fmt.Errorf("error opening access log: %w", err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	f.file = file
+	f.size = info.Size()
+	f.opened = time.Now()
+
+	return nil
+}
+
+// Write method writes a log line, the file is rotated before if needed.
+func (f *rotatingFile) Write(p []byte) (int, error) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+
+	if f.needsRotation(int64(len(p))) {
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+
+	return n, err
+}
+
+func (f *rotatingFile) needsRotation(n int64) bool {
+	if f.size == 0 {
+		return false
+	}
+
+	if f.rotation.MaxSize > 0 && f.size+n > int64(f.rotation.MaxSize) {
+		return true
+	}
+
+	return f.rotation.Interval > 0 && time.Since(f.opened) >= f.rotation.Interval
+}
+
+func (f *rotatingFile) rotate() error {
+	if err := f.file.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Rename(f.path, f.backupName(time.Now())); err != nil {
+		return fmt.Errorf("error rotating access log: %w", err)
+	}
+
+	if err := f.open(); err != nil {
+		return err
+	}
+
+	go f.removeOldBackups()
+
+	return nil
+}
+
+// backupName method returns an unused name for a rotated file, the time is
+// moved forward when the file was already rotated in the same millisecond.
+func (f *rotatingFile) backupName(t time.Time) string {
+	ext := filepath.Ext(f.path)
+	for {
+		name := strings.TrimSuffix(f.path, ext) + "-" + t.Format(backupTimeFormat) + ext
+		if _, err := os.Lstat(name); err != nil {
+			return name
+		}
+		t = t.Add(time.Millisecond)
+	}
+}
+
+// removeOldBackups method applies the retention of the rotated files.
+func (f *rotatingFile) removeOldBackups() {
+	if f.rotation.MaxBackups <= 0 && f.rotation.MaxAge <= 0 {
+		return
+	}
+
+	ext := filepath.Ext(f.path)
+	matches, err := filepath.Glob(strings.TrimSuffix(f.path, ext) + "-*" + ext)
+	if err != nil {
+		return
+	}
+
+	type backup struct {
+		path string
+		time time.Time
+	}
+
+	var backups []backup
+	for _, m := range matches {
+		stamp := strings.TrimSuffix(strings.TrimPrefix(m, strings.TrimSuffix(f.path, ext)+"-"), ext)
+		for _, format := range []string{backupTimeFormat, legacyBackupTimeFormat} {
+			if t, err := time.ParseInLocation(format, stamp, time.Local); err == nil {
+				backups = append(backups, backup{path: m, time: t})
+				break
+			}
+		}
+	}
+
+	// newest first
+	slices.SortFunc(backups, func(a, b backup) int { return b.time.Compare(a.time) })
+
+	for i, b := range backups {
+		if (f.rotation.MaxBackups > 0 && i >= f.rotation.MaxBackups) ||
+			(f.rotation.MaxAge > 0 && time.Since(b.time) > f.rotation.MaxAge) {
+			_ = os.Remove(b.path)
+		}
+	}
+}
+
+func (f *rotatingFile) Close() error {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+
+	return f.file.Close()
+}
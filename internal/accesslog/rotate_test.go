@@ -0,0 +1,132 @@
+// SPDX-FileCopyrightText: 2025 Hector @sudosu404 <hector@email.gnx>
+// SPDX-License-Identifier: AGPL3
+
+package accesslog
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sudosu404/tailnet-lib/internal/model"
+)
+
+func TestRotatingFileKeepsAllLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "access.log")
+
+	f, err := openRotatingFile(path, model.LogRotation{MaxSize: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	// every line rotates the file, several times in the same second
+	lines := []string{"line-0001\n", "line-0002\n", "line-0003\n", "line-0004\n"}
+	for _, line := range lines {
+		if _, err := f.Write([]byte(line)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	files, err := filepath.Glob(filepath.Join(filepath.Dir(path), "access*.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(files) != len(lines) {
+		t.Fatalf("got %d files, want %d: %v", len(files), len(lines), files)
+	}
+
+	var content strings.Builder
+	for _, file := range files {
+		b, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		content.Write(b)
+	}
+
+	for _, line := range lines {
+		if !strings.Contains(content.String(), line) {
+			t.Errorf("line %q lost", line)
+		}
+	}
+}
+
+func TestBackupNameIsUnused(t *testing.T) {
+	f := &rotatingFile{path: filepath.Join(t.TempDir(), "access.log")}
+	now := time.Date(2025, 1, 2, 15, 4, 5, 0, time.Local)
+
+	first := f.backupName(now)
+	if err := os.WriteFile(first, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	second := f.backupName(now)
+	if second == first {
+		t.Fatalf("backup name %q reused", second)
+	}
+
+	if want := "access-20250102T150405.001.log"; filepath.Base(second) != want {
+		t.Errorf("got %q, want %q", filepath.Base(second), want)
+	}
+}
+
+func TestRemoveOldBackups(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+
+	backups := []string{
+		"access-" + now.Add(-time.Hour).Format(backupTimeFormat) + ".log",
+		"access-" + now.Add(-2*time.Hour).Format(backupTimeFormat) + ".log",
+		"access-" + now.Add(-3*time.Hour).Format(legacyBackupTimeFormat) + ".log",
+		"access-" + now.Add(-48*time.Hour).Format(backupTimeFormat) + ".log",
+		"access-other.log",
+	}
+	for _, name := range backups {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		rotation model.LogRotation
+		kept     []string
+	}{
+		{
+			name:     "max backups",
+			rotation: model.LogRotation{MaxBackups: 3},
+			kept:     backups[:3],
+		},
+		{
+			name:     "max age",
+			rotation: model.LogRotation{MaxAge: 150 * time.Minute},
+			kept:     backups[:2],
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &rotatingFile{path: filepath.Join(dir, "access.log"), rotation: tt.rotation}
+			f.removeOldBackups()
+
+			for _, name := range tt.kept {
+				if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+					t.Errorf("backup %s removed", name)
+				}
+			}
+
+			// files without a rotation time are never removed
+			if _, err := os.Stat(filepath.Join(dir, "access-other.log")); err != nil {
+				t.Error("unrelated file removed")
+			}
+		})
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, backups[3])); err == nil {
+		t.Errorf("backup %s not removed", backups[3])
+	}
+}
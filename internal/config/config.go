@@ -10,6 +10,8 @@ import (
 	"io/fs"
 	"os"
 
+	"github.com/sudosu404/tailnet-lib/internal/model"
+
 	"github.com/creasty/defaults"
 	"github.com/rs/zerolog/log"
 )
@@ -24,10 +26,13 @@ type (
 		Lists     map[string]*ListTargetProviderConfig   `validate:"dive,required" yaml:"lists"`
 		Tailscale TailscaleProxyProviderConfig           `yaml:"tailscale"`
 
-		HTTP HTTPConfig `yaml:"http"`
-		Log  LogConfig  `yaml:"log"`
+		HTTP          HTTPConfig          `yaml:"http"`
+		Log           LogConfig           `yaml:"log"`
+		Tracing       TracingConfig       `yaml:"tracing"`
+		IdentityToken IdentityTokenConfig `yaml:"identityToken"`
 
-		ProxyAccessLog bool `validate:"boolean" default:"true" yaml:"proxyAccessLog"`
+		ProxyAccessLog bool            `validate:"boolean" default:"true" yaml:"proxyAccessLog"`
+		AccessLog      model.AccessLog `validate:"dive" yaml:"accessLog"`
 	}
 
 	// LogConfig stores logging configuration.
@@ -36,6 +41,25 @@ type (
 		JSON  bool   `validate:"boolean" default:"false" yaml:"json"`
 	}
 
+	// TracingConfig stores OpenTelemetry tracing configuration.
+	TracingConfig struct {
+		Enabled bool `validate:"boolean" default:"false" yaml:"enabled"`
+		// Endpoint of the OTLP http receiver, ex: otel-collector:4318
+		Endpoint    string            `validate:"required_if=Enabled true" yaml:"endpoint,omitempty"`
+		Insecure    bool              `validate:"boolean" default:"false" yaml:"insecure"`
+		Headers     map[string]string `yaml:"headers,omitempty"`
+		ServiceName string            `default:"tailnet" yaml:"serviceName"`
+		SampleRatio float64           `validate:"min=0,max=1" default:"1" yaml:"sampleRatio"`
+	}
+
+	// IdentityTokenConfig stores the configuration of the signed identity tokens.
+	IdentityTokenConfig struct {
+		// KeyFile stores the signing key, generated if it doesn't exist.
+		// Defaults to identity.key in the Tailscale data directory.
+		KeyFile string `validate:"omitempty" yaml:"keyFile,omitempty"`
+		Issuer  string `validate:"required" default:"tailnet" yaml:"issuer"`
+	}
+
 	// HTTPConfig stores HTTP configuration.
 	HTTPConfig struct {
 		Hostname string `validate:"ip|hostname,required" default:"0.0.0.0" yaml:"hostname"`
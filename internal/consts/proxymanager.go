@@ -7,4 +7,6 @@ const (
 	HeaderUsername      = "X-tailnet-username"
 	HeaderDisplayName   = "x-tailnet-displayName"
 	HeaderProfilePicURL = "x-tailnet-profilePicUrl"
+	HeaderIdentityToken = "X-tailnet-identity-token"
+	HeaderCapabilities  = "X-tailnet-capabilities"
 )
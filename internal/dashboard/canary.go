@@ -0,0 +1,60 @@
+// SPDX-FileCopyrightText: 2025 Hector @sudosu404 <hector@email.gnx>
+// SPDX-License-Identifier: AGPL3
+
+package dashboard
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/sudosu404/tailnet-lib/internal/model"
+	"github.com/sudosu404/tailnet-lib/internal/proxymanager"
+)
+
+type (
+	// canaryWeight struct is the body of the canary endpoint.
+	canaryWeight struct {
+		// Port is the port name, like "443/https".
+		Port   string  `json:"port"`
+		Weight float64 `json:"weight"`
+	}
+)
+
+// getCanaryHandler method returns the canary weights of the proxy ports.
+func (dash *Dashboard) getCanaryHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		p, ok := dash.pm.GetProxy(r.PathValue("name"))
+		if !ok {
+			dash.HTTP.JSONResponseCode(w, r, apiError{proxymanager.ErrProxyNotFound.Error()}, http.StatusNotFound)
+			return
+		}
+
+		dash.HTTP.JSONResponse(w, r, p.GetCanaries())
+	}
+}
+
+// setCanaryHandler method changes the canary weight of a proxy port.
+func (dash *Dashboard) setCanaryHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var state canaryWeight
+		if err := json.NewDecoder(r.Body).Decode(&state); err != nil {
+			dash.HTTP.JSONResponseCode(w, r, apiError{err.Error()}, http.StatusBadRequest)
+			return
+		}
+
+		if err := dash.pm.SetCanaryWeight(r.PathValue("name"), state.Port, state.Weight); err != nil {
+			code := http.StatusInternalServerError
+			switch {
+			case errors.Is(err, proxymanager.ErrProxyNotFound), errors.Is(err, proxymanager.ErrCanaryNotFound):
+				code = http.StatusNotFound
+			case errors.Is(err, model.ErrInvalidPercent):
+				code = http.StatusBadRequest
+			}
+			dash.HTTP.JSONResponseCode(w, r, apiError{err.Error()}, code)
+			return
+		}
+
+		dash.getCanaryHandler()(w, r)
+	}
+}
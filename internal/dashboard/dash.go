@@ -4,6 +4,10 @@
 package dashboard
 
 import (
+	"errors"
+	"mime"
+	"net/http"
+	"net/url"
 	"sync"
 
 	"github.com/sudosu404/tailnet-lib/internal/core"
@@ -15,6 +19,11 @@ import (
 	"github.com/rs/zerolog"
 )
 
+var (
+	ErrJSONContentType = errors.New("content type must be application/json")
+	ErrCrossOrigin     = errors.New("cross origin requests are not allowed")
+)
+
 type Dashboard struct {
 	Log        zerolog.Logger
 	HTTP       *core.HTTPServer
@@ -39,9 +48,57 @@ func NewDashboard(http *core.HTTPServer, log zerolog.Logger, pm *proxymanager.Pr
 // AddRoutes method add dashboard related routes to the http server
 func (dash *Dashboard) AddRoutes() {
 	dash.HTTP.Get("/stream", dash.streamHandler())
+	dash.HTTP.Get("/api/proxies/{name}/maintenance", dash.getMaintenanceHandler())
+	dash.HTTP.Post("/api/proxies/{name}/maintenance", dash.apiWriteMiddleware(dash.setMaintenanceHandler()))
+	dash.HTTP.Get("/api/proxies/{name}/canary", dash.getCanaryHandler())
+	dash.HTTP.Post("/api/proxies/{name}/canary", dash.apiWriteMiddleware(dash.setCanaryHandler()))
 	dash.HTTP.Get("/", web.Static)
 }
 
+// apiWriteMiddleware method protects the API endpoints that change the proxies
+// from cross site requests: the body must be JSON, which browsers can't send
+// cross origin without a preflight, and browser requests must be same origin.
+func (dash *Dashboard) apiWriteMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		if err != nil || mediaType != "application/json" {
+			dash.HTTP.JSONResponseCode(w, r, apiError{ErrJSONContentType.Error()}, http.StatusUnsupportedMediaType)
+			return
+		}
+
+		if !isSameOrigin(r) {
+			dash.HTTP.JSONResponseCode(w, r, apiError{ErrCrossOrigin.Error()}, http.StatusForbidden)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// isSameOrigin function returns false for browser requests from another origin.
+// Requests without Sec-Fetch-Site and Origin headers, like curl, are allowed.
+func isSameOrigin(r *http.Request) bool {
+	switch r.Header.Get("Sec-Fetch-Site") {
+	case "":
+	case "same-origin", "none":
+		return true
+	default:
+		return false
+	}
+
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+
+	return u.Host == r.Host
+}
+
 // index is the HandlerFunc to index page of dashboard
 func (dash *Dashboard) renderList(ch chan SSEMessage) {
 	dash.mtx.RLock()
@@ -56,7 +113,7 @@ func (dash *Dashboard) renderList(ch chan SSEMessage) {
 	proxies := dash.pm.GetProxies()
 	_ = proxies
 	for name, p := range dash.pm.Proxies {
-		if p.Config.Dashboard.Visible {
+		if p.GetConfig().Dashboard.Visible {
 			dash.renderProxy(ch, name, EventAppend)
 		}
 	}
@@ -77,20 +134,26 @@ func (dash *Dashboard) renderProxy(ch chan SSEMessage, name string, ev EventType
 		url = p.GetAuthURL()
 	}
 
-	icon := p.Config.Dashboard.Icon
+	pcfg := p.GetConfig()
+
+	icon := pcfg.Dashboard.Icon
 	if icon == "" {
 		icon = model.DefaultDashboardIcon
 	}
 
-	label := p.Config.Dashboard.Label
+	label := pcfg.Dashboard.Label
 	if label == "" {
 		label = name
 	}
 
-	ports := make([]model.PortConfig, len(p.Config.Ports))
+	health := p.GetTargetsHealth()
+	targetsHealth := make(map[string]map[string]model.TargetHealth, len(health))
+
+	ports := make([]model.PortConfig, len(pcfg.Ports))
 	i := 0
-	for _, target := range p.Config.Ports {
+	for k, target := range pcfg.Ports {
 		ports[i] = target
+		targetsHealth[target.String()] = health[k]
 		i++
 	}
 
@@ -104,6 +167,8 @@ func (dash *Dashboard) renderProxy(ch chan SSEMessage, name string, ev EventType
 		Icon:        icon,
 		Label:       label,
 		Ports:       ports,
+
+		TargetsHealth: targetsHealth,
 	}
 
 	ch <- SSEMessage{
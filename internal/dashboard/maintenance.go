@@ -0,0 +1,84 @@
+// SPDX-FileCopyrightText: 2025 Hector @sudosu404 <hector@email.gnx>
+// SPDX-License-Identifier: AGPL3
+
+package dashboard
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/sudosu404/tailnet-lib/internal/model"
+	"github.com/sudosu404/tailnet-lib/internal/proxymanager"
+)
+
+type (
+	// maintenanceState struct is the body of the maintenance endpoints.
+	maintenanceState struct {
+		Enabled bool   `json:"enabled"`
+		Message string `json:"message,omitempty"`
+		// RetryAfter is a duration like "10m".
+		RetryAfter string `json:"retryAfter,omitempty"`
+	}
+
+	apiError struct {
+		Message string `json:"message"`
+	}
+)
+
+// getMaintenanceHandler method returns the maintenance mode of a proxy.
+func (dash *Dashboard) getMaintenanceHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		p, ok := dash.pm.GetProxy(r.PathValue("name"))
+		if !ok {
+			dash.HTTP.JSONResponseCode(w, r, apiError{proxymanager.ErrProxyNotFound.Error()}, http.StatusNotFound)
+			return
+		}
+
+		m := p.GetMaintenance()
+		state := maintenanceState{
+			Enabled:    m.Enabled,
+			Message:    m.GetMessage(),
+			RetryAfter: m.GetRetryAfter().String(),
+		}
+
+		dash.HTTP.JSONResponse(w, r, state)
+	}
+}
+
+// setMaintenanceHandler method enables or disables the maintenance mode of a proxy.
+func (dash *Dashboard) setMaintenanceHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var state maintenanceState
+		if err := json.NewDecoder(r.Body).Decode(&state); err != nil {
+			dash.HTTP.JSONResponseCode(w, r, apiError{err.Error()}, http.StatusBadRequest)
+			return
+		}
+
+		m := model.Maintenance{
+			Enabled: state.Enabled,
+			Message: state.Message,
+		}
+
+		if state.RetryAfter != "" {
+			d, err := time.ParseDuration(state.RetryAfter)
+			if err != nil {
+				dash.HTTP.JSONResponseCode(w, r, apiError{err.Error()}, http.StatusBadRequest)
+				return
+			}
+			m.RetryAfter = d
+		}
+
+		if err := dash.pm.SetMaintenance(r.PathValue("name"), m); err != nil {
+			code := http.StatusInternalServerError
+			if errors.Is(err, proxymanager.ErrProxyNotFound) {
+				code = http.StatusNotFound
+			}
+			dash.HTTP.JSONResponseCode(w, r, apiError{err.Error()}, code)
+			return
+		}
+
+		dash.getMaintenanceHandler()(w, r)
+	}
+}
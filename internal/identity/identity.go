@@ -0,0 +1,369 @@
+// SPDX-FileCopyrightText: 2025 Hector @sudosu404 <hector@email.gnx>
+// SPDX-License-Identifier: AGPL3
+
+// Package identity signs the identity tokens forwarded to the upstreams
+// and publishes the signing key as a JWKS.
+package identity
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sudosu404/tailnet-lib/internal/config"
+	"github.com/sudosu404/tailnet-lib/internal/consts"
+	"github.com/sudosu404/tailnet-lib/internal/core"
+	"github.com/sudosu404/tailnet-lib/internal/model"
+
+	"github.com/rs/zerolog"
+)
+
+type (
+	// Signer struct signs JWTs with an ECDSA P-256 key (ES256).
+	Signer struct {
+		key  *ecdsa.PrivateKey
+		kid  string
+		jwks []byte
+	}
+
+	// Claims struct stores the claims of an identity token.
+	Claims struct {
+		Issuer            string   `json:"iss"`
+		Subject           string   `json:"sub"`
+		Audience          string   `json:"aud,omitempty"`
+		IssuedAt          int64    `json:"iat"`
+		NotBefore         int64    `json:"nbf"`
+		ExpiresAt         int64    `json:"exp"`
+		PreferredUsername string   `json:"preferred_username,omitempty"`
+		Email             string   `json:"email,omitempty"`
+		Name              string   `json:"name,omitempty"`
+		Picture           string   `json:"picture,omitempty"`
+		Node              string   `json:"node,omitempty"`
+		NodeID            string   `json:"node_id,omitempty"`
+		Tags              []string `json:"tags,omitempty"`
+		// Capabilities are the app capabilities granted in the tailnet policy file.
+		Capabilities map[string][]json.RawMessage `json:"capabilities,omitempty"`
+	}
+
+	jwk struct {
+		Kty string `json:"kty"`
+		Crv string `json:"crv"`
+		X   string `json:"x"`
+		Y   string `json:"y"`
+		Kid string `json:"kid"`
+		Use string `json:"use"`
+		Alg string `json:"alg"`
+	}
+
+	jwtHeader struct {
+		Alg string `json:"alg"`
+		Typ string `json:"typ"`
+		Kid string `json:"kid"`
+	}
+)
+
+const (
+	// JWKSPath is the path of the JWKS endpoint in the main http server.
+	JWKSPath = "/.well-known/jwks.json"
+
+	defaultKeyFile = "identity.key"
+	pemType        = "EC PRIVATE KEY"
+	coordSize      = 32
+)
+
+var (
+	ErrNotInitialized = errors.New("identity signer is not initialized")
+	ErrInvalidKey     = errors.New("invalid identity key, expected an ECDSA P-256 private key")
+
+	defaultSigner  *Signer
+	defaultKeyPath string
+	logger         zerolog.Logger
+	mtx            sync.RWMutex
+)
+
+// Init function configures the signing key file of tailnet.yaml and loads the key if it exists.
+// A missing key is generated by the first identity token, errors are logged and
+// only disable the identity tokens.
+func Init(log zerolog.Logger) {
+	keyFile := config.Config.IdentityToken.KeyFile
+	if keyFile == "" {
+		keyFile = filepath.Join(config.Config.Tailscale.DataDir, defaultKeyFile)
+	}
+
+	log = log.With().Str("module", "identity").Str("file", keyFile).Logger()
+
+	mtx.Lock()
+	defer mtx.Unlock()
+
+	defaultKeyPath = keyFile
+	logger = log
+	defaultSigner = nil
+
+	key, err := loadKey(keyFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return
+	}
+
+	if err == nil {
+		defaultSigner, err = newSigner(key)
+	}
+	if err != nil {
+		log.Error().Err(err).Msg("error loading identity signing key")
+		return
+	}
+
+	log.Info().Str("kid", defaultSigner.kid).Msg("identity signing key loaded")
+}
+
+// Sign function signs the claims with the key configured by Init,
+// the key is generated the first time if it doesn't exist.
+func Sign(claims *Claims) (string, error) {
+	signer, err := getSigner()
+	if err != nil {
+		return "", err
+	}
+
+	return signer.Sign(claims)
+}
+
+// LoadKey function loads or generates the signing key, it's called by the ports
+// that enable identity tokens so the JWKS is published before the first token.
+func LoadKey() error {
+	_, err := getSigner()
+	return err
+}
+
+// getSigner function returns the default signer, loading or generating its key if needed.
+func getSigner() (*Signer, error) {
+	mtx.RLock()
+	signer := defaultSigner
+	mtx.RUnlock()
+
+	if signer != nil {
+		return signer, nil
+	}
+
+	mtx.Lock()
+	defer mtx.Unlock()
+
+	if defaultSigner != nil {
+		return defaultSigner, nil
+	}
+
+	if defaultKeyPath == "" {
+		return nil, ErrNotInitialized
+	}
+
+	signer, err := NewSigner(defaultKeyPath)
+	if err != nil {
+		return nil, err
+	}
+
+	defaultSigner = signer
+	logger.Info().Str("kid", signer.kid).Msg("identity signing key loaded")
+
+	return signer, nil
+}
+
+// AddRoutes function adds the JWKS endpoint to the http server.
+func AddRoutes(http *core.HTTPServer) {
+	http.Get(JWKSPath, jwksHandler())
+}
+
+func jwksHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		mtx.RLock()
+		signer := defaultSigner
+		mtx.RUnlock()
+
+		if signer == nil {
+			http.Error(w, ErrNotInitialized.Error(), http.StatusServiceUnavailable)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "public, max-age=3600")
+		_, _ = w.Write(signer.jwks)
+	})
+}
+
+// NewClaims function returns the claims of a Tailscale identity.
+func NewClaims(who model.Whois, audience string, ttl time.Duration) *Claims {
+	now := time.Now()
+
+	c := &Claims{
+		Issuer:            config.Config.IdentityToken.Issuer,
+		Subject:           who.ID,
+		Audience:          audience,
+		IssuedAt:          now.Unix(),
+		NotBefore:         now.Unix(),
+		ExpiresAt:         now.Add(ttl).Unix(),
+		PreferredUsername: who.Username,
+		Name:              who.DisplayName,
+		Picture:           who.ProfilePicURL,
+		Node:              who.GetNodeName(),
+		NodeID:            who.GetNodeID(),
+		Tags:              who.GetTags(),
+		Capabilities:      who.GetCapabilities(),
+	}
+
+	if isEmailAddress(who.Username) {
+		c.Email = who.Username
+	}
+
+	return c
+}
+
+// isEmailAddress function returns true if a Tailscale login is an email address.
+// Logins of identity providers without email, like "user@github" or
+// "user@passkey", have no domain with a dot and are not sent as email.
+func isEmailAddress(login string) bool {
+	addr, err := mail.ParseAddress(login)
+	if err != nil || addr.Address != login || addr.Name != "" {
+		return false
+	}
+
+	_, domain, _ := strings.Cut(login, "@")
+
+	return strings.Contains(strings.Trim(domain, "."), ".")
+}
+
+// NewSigner function returns a Signer with the key stored in keyFile,
+// the key is generated and saved if the file doesn't exist.
+func NewSigner(keyFile string) (*Signer, error) {
+	key, err := loadKey(keyFile)
+	if errors.Is(err, os.ErrNotExist) {
+		key, err = generateKey(keyFile)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return newSigner(key)
+}
+
+func newSigner(key *ecdsa.PrivateKey) (*Signer, error) {
+	pub, err := key.PublicKey.ECDH()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidKey, err)
+	}
+
+	// uncompressed point: 0x04 || X || Y
+	point := pub.Bytes()
+	k := jwk{
+		Kty: "EC",
+		Crv: "P-256",
+		X:   b64(point[1 : 1+coordSize]),
+		Y:   b64(point[1+coordSize:]),
+		Use: "sig",
+		Alg: "ES256",
+	}
+
+	// RFC 7638 thumbprint, the members are in lexicographic order
+	thumbprint := sha256.Sum256([]byte(`{"crv":"` + k.Crv + `","kty":"` + k.Kty + `","x":"` + k.X + `","y":"` + k.Y + `"}`))
+	k.Kid = b64(thumbprint[:])
+
+	jwks, err := json.Marshal(map[string][]jwk{"keys": {k}})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Signer{key: key, kid: k.Kid, jwks: jwks}, nil
+}
+
+// Sign method returns the claims as a signed compact JWT.
+func (s *Signer) Sign(claims *Claims) (string, error) {
+	header, err := json.Marshal(jwtHeader{Alg: "ES256", Typ: "JWT", Kid: s.kid})
+	if err != nil {
+		return "", err
+	}
+
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+
+	signingInput := b64(header) + "." + b64(payload)
+	hash := sha256.Sum256([]byte(signingInput))
+
+	r, sig, err := ecdsa.Sign(rand.Reader, s.key, hash[:])
+	if err != nil {
+		return "", fmt.Errorf("error signing identity token: %w", err)
+	}
+
+	// JWS uses the fixed size R || S encoding instead of ASN.1
+	signature := make([]byte, 2*coordSize) //nolint:mnd
+	r.FillBytes(signature[:coordSize])
+	sig.FillBytes(signature[coordSize:])
+
+	return signingInput + "." + b64(signature), nil
+}
+
+// JWKS method returns the public key as a JSON Web Key Set.
+func (s *Signer) JWKS() []byte {
+	return s.jwks
+}
+
+func loadKey(keyFile string) (*ecdsa.PrivateKey, error) {
+	data, err := os.ReadFile(keyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != pemType {
+		return nil, ErrInvalidKey
+	}
+
+	key, err := x509.ParseECPrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidKey, err)
+	}
+
+	if key.Curve != elliptic.P256() {
+		return nil, ErrInvalidKey
+	}
+
+	return key, nil
+}
+
+func generateKey(keyFile string) (*ecdsa.PrivateKey, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("error generating identity key: %w", err)
+	}
+
+	der, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(keyFile), consts.PermOwnerAll); err != nil {
+		return nil, err
+	}
+
+	data := pem.EncodeToMemory(&pem.Block{Type: pemType, Bytes: der})
+	if err := os.WriteFile(keyFile, data, consts.PermOwnerRead|consts.PermOwnerWrite); err != nil {
+		return nil, fmt.Errorf("error saving identity key: %w", err)
+	}
+
+	return key, nil
+}
+
+func b64(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
@@ -0,0 +1,126 @@
+// SPDX-FileCopyrightText: 2025 Hector @sudosu404 <hector@email.gnx>
+// SPDX-License-Identifier: AGPL3
+
+package identity
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+func TestIsEmailAddress(t *testing.T) {
+	tests := []struct {
+		login string
+		want  bool
+	}{
+		{login: "user@example.com", want: true},
+		{login: "first.last@mail.example.org", want: true},
+		{login: "user@github", want: false},
+		{login: "user@passkey", want: false},
+		{login: "user@example.", want: false},
+		{login: "user", want: false},
+		{login: "", want: false},
+		{login: "User <user@example.com>", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.login, func(t *testing.T) {
+			if got := isEmailAddress(tt.login); got != tt.want {
+				t.Errorf("isEmailAddress(%q) = %v, want %v", tt.login, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSignVerifiesWithJWKS(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := newSigner(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	token, err := s.Sign(&Claims{Subject: "123", Email: "user@example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		t.Fatalf("got %d token parts, want 3", len(parts))
+	}
+
+	var header jwtHeader
+	decodeJSON(t, parts[0], &header)
+	if header.Alg != "ES256" || header.Typ != "JWT" {
+		t.Errorf("unexpected header %+v", header)
+	}
+
+	var claims Claims
+	decodeJSON(t, parts[1], &claims)
+	if claims.Subject != "123" || claims.Email != "user@example.com" {
+		t.Errorf("unexpected claims %+v", claims)
+	}
+
+	var jwks struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(s.JWKS(), &jwks); err != nil {
+		t.Fatal(err)
+	}
+	if len(jwks.Keys) != 1 {
+		t.Fatalf("got %d keys, want 1", len(jwks.Keys))
+	}
+
+	k := jwks.Keys[0]
+	if k.Kid != header.Kid {
+		t.Errorf("got kid %q, want %q", header.Kid, k.Kid)
+	}
+
+	pub := &ecdsa.PublicKey{
+		Curve: elliptic.P256(),
+		X:     new(big.Int).SetBytes(decode(t, k.X)),
+		Y:     new(big.Int).SetBytes(decode(t, k.Y)),
+	}
+
+	signature := decode(t, parts[2])
+	if len(signature) != 2*coordSize {
+		t.Fatalf("got signature of %d bytes, want %d", len(signature), 2*coordSize)
+	}
+
+	hash := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	r := new(big.Int).SetBytes(signature[:coordSize])
+	sig := new(big.Int).SetBytes(signature[coordSize:])
+	if !ecdsa.Verify(pub, hash[:], r, sig) {
+		t.Error("signature doesn't verify with the JWKS key")
+	}
+}
+
+func decode(t *testing.T, s string) []byte {
+	t.Helper()
+
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return b
+}
+
+func decodeJSON(t *testing.T, s string, v any) {
+	t.Helper()
+
+	if err := json.Unmarshal(decode(t, s), v); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,173 @@
+// SPDX-FileCopyrightText: 2025 Hector @sudosu404 <hector@email.gnx>
+// SPDX-License-Identifier: AGPL3
+
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/sudosu404/tailnet-lib/internal/core"
+	"github.com/sudosu404/tailnet-lib/internal/model"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "tailnet"
+
+var (
+	// Registry stores all the tailnet metrics.
+	Registry = prometheus.NewRegistry()
+
+	requestLabels = []string{"proxy", "port", "code", "upstream"}
+
+	requestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "Total number of http requests handled by the proxy ports.",
+	}, requestLabels)
+
+	requestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Latency of the http requests handled by the proxy ports.",
+		Buckets:   prometheus.DefBuckets,
+	}, requestLabels)
+
+	requestBytes = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_request_bytes_total",
+		Help:      "Total bytes received in the body of the http requests.",
+	}, requestLabels)
+
+	responseBytes = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_response_bytes_total",
+		Help:      "Total bytes sent in the body of the http responses.",
+	}, requestLabels)
+
+	proxyStatus = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "proxy_status",
+		Help:      "Current status of the proxy, 1 for the active status.",
+	}, []string{"proxy", "status"})
+
+	targetHealth = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "target_healthy",
+		Help:      "Health check result of a port target, 1 healthy and 0 unhealthy.",
+	}, []string{"proxy", "port", "target"})
+
+	targetProviderEvents = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "target_provider_events_total",
+		Help:      "Total number of events sent by the target providers.",
+	}, []string{"provider", "action"})
+
+	mirrorRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "mirror_requests_total",
+		Help:      "Total number of requests copied to the mirror targets, by result.",
+	}, []string{"proxy", "port", "result"})
+
+	nodeState = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "tsnet_node_state",
+		Help:      "Current backend state of the tsnet node of the proxy, 1 for the active state.",
+	}, []string{"proxy", "state"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		requestsTotal,
+		requestDuration,
+		requestBytes,
+		responseBytes,
+		proxyStatus,
+		targetHealth,
+		targetProviderEvents,
+		mirrorRequests,
+		nodeState,
+	)
+}
+
+// Handler function returns the http handler of the metrics endpoint.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
+
+// AddRoutes function adds the metrics endpoint to the http server.
+func AddRoutes(http *core.HTTPServer) {
+	http.Get("/metrics", Handler())
+}
+
+// ObserveRequest function records a request handled by a proxy port.
+func ObserveRequest(proxy, port, upstream string, code int, duration time.Duration, bytesIn, bytesOut int64) {
+	labels := prometheus.Labels{
+		"proxy":    proxy,
+		"port":     port,
+		"code":     strconv.Itoa(code),
+		"upstream": upstream,
+	}
+
+	requestsTotal.With(labels).Inc()
+	requestDuration.With(labels).Observe(duration.Seconds())
+	requestBytes.With(labels).Add(float64(bytesIn))
+	responseBytes.With(labels).Add(float64(bytesOut))
+}
+
+// SetProxyStatus function sets the active status of a proxy.
+func SetProxyStatus(proxy string, status model.ProxyStatus) {
+	proxyStatus.DeletePartialMatch(prometheus.Labels{"proxy": proxy})
+	proxyStatus.WithLabelValues(proxy, status.String()).Set(1)
+}
+
+// SetTargetHealth function sets the health of a port target.
+func SetTargetHealth(proxy, port, target string, health model.TargetHealth) {
+	value := 0.0
+	if health == model.TargetHealthHealthy {
+		value = 1
+	}
+
+	targetHealth.WithLabelValues(proxy, port, target).Set(value)
+}
+
+// IncTargetProviderEvent function counts an event of a target provider.
+func IncTargetProviderEvent(provider, action string) {
+	targetProviderEvents.WithLabelValues(provider, action).Inc()
+}
+
+// IncMirrorRequest function counts a request copied to the mirror target of a port.
+func IncMirrorRequest(proxy, port, result string) {
+	mirrorRequests.WithLabelValues(proxy, port, result).Inc()
+}
+
+// SetNodeState function sets the active backend state of the tsnet node of a proxy.
+func SetNodeState(proxy, state string) {
+	nodeState.DeletePartialMatch(prometheus.Labels{"proxy": proxy})
+	nodeState.WithLabelValues(proxy, state).Set(1)
+}
+
+// DeletePortTargets function removes the target health series of a port.
+func DeletePortTargets(proxy, port string) {
+	targetHealth.DeletePartialMatch(prometheus.Labels{"proxy": proxy, "port": port})
+}
+
+// DeleteProxy function removes all the series of a proxy.
+func DeleteProxy(proxy string) {
+	labels := prometheus.Labels{"proxy": proxy}
+
+	requestsTotal.DeletePartialMatch(labels)
+	requestDuration.DeletePartialMatch(labels)
+	requestBytes.DeletePartialMatch(labels)
+	responseBytes.DeletePartialMatch(labels)
+	proxyStatus.DeletePartialMatch(labels)
+	targetHealth.DeletePartialMatch(labels)
+	mirrorRequests.DeletePartialMatch(labels)
+	nodeState.DeletePartialMatch(labels)
+}
@@ -0,0 +1,73 @@
+// SPDX-FileCopyrightText: 2025 Hector @sudosu404 <hector@email.gnx>
+// SPDX-License-Identifier: AGPL3
+
+package model
+
+import (
+	"time"
+)
+
+type (
+	// AccessLog struct stores the destination and format of the access logs.
+	// Empty values of a proxy use the values of tailnet.yaml.
+	AccessLog struct {
+		// Format is "common", "combined" or "json".
+		Format string `validate:"omitempty,oneof=common combined json" yaml:"format,omitempty"`
+		// Output is "stdout", "stderr" or a file path.
+		Output   string      `yaml:"output,omitempty"`
+		Rotation LogRotation `validate:"dive" yaml:"rotation,omitempty"`
+	}
+
+	// LogRotation struct stores the rotation and retention of the access log files.
+	LogRotation struct {
+		// MaxSize rotates the file when it's larger.
+		MaxSize ByteSize `yaml:"maxSize,omitempty"`
+		// Interval rotates the file periodically, like every 24h.
+		Interval time.Duration `yaml:"interval,omitempty"`
+		// MaxBackups is the number of rotated files kept, zero keeps all of them.
+		MaxBackups int `yaml:"maxBackups,omitempty"`
+		// MaxAge deletes the rotated files older than this, zero keeps all of them.
+		MaxAge time.Duration `yaml:"maxAge,omitempty"`
+	}
+)
+
+const (
+	AccessLogCommon   = "common"
+	AccessLogCombined = "combined"
+	AccessLogJSON     = "json"
+
+	AccessLogStdout = "stdout"
+	AccessLogStderr = "stderr"
+
+	DefaultAccessLogFormat = AccessLogJSON
+	DefaultAccessLogOutput = AccessLogStdout
+)
+
+// Merge method returns the access log with the empty values taken from defaults.
+func (a AccessLog) Merge(defaults AccessLog) AccessLog {
+	if a.Format == "" {
+		a.Format = defaults.Format
+	}
+
+	// the rotation belongs to the output file
+	if a.Output == "" {
+		a.Output = defaults.Output
+		a.Rotation = defaults.Rotation
+	}
+
+	return a
+}
+
+func (a *AccessLog) GetFormat() string {
+	if a.Format == "" {
+		return DefaultAccessLogFormat
+	}
+	return a.Format
+}
+
+func (a *AccessLog) GetOutput() string {
+	if a.Output == "" {
+		return DefaultAccessLogOutput
+	}
+	return a.Output
+}
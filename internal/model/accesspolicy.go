@@ -0,0 +1,113 @@
+// SPDX-FileCopyrightText: 2025 Hector @sudosu404 <hector@email.gnx>
+// SPDX-License-Identifier: AGPL3
+
+package model
+
+import (
+	"path"
+	"slices"
+	"strings"
+)
+
+type (
+	// AccessPolicy struct stores the identity based access control of a proxy or port.
+	// Deny rules have priority, when allow rules are defined only matching identities are allowed.
+	AccessPolicy struct {
+		Allow AccessRule `validate:"dive" yaml:"allow,omitempty"`
+		Deny  AccessRule `validate:"dive" yaml:"deny,omitempty"`
+	}
+
+	// AccessRule struct matches a Tailscale identity, any matching field matches the rule.
+	// Users, tags, nodes and capabilities accept wildcards like "*@example.com".
+	AccessRule struct {
+		// Users are Tailscale login names.
+		Users   []string `yaml:"users,omitempty"`
+		UserIDs []string `yaml:"userIDs,omitempty"`
+		Tags    []string `yaml:"tags,omitempty"`
+		Nodes   []string `yaml:"nodes,omitempty"`
+		// Capabilities are app capabilities granted in the tailnet policy file.
+		Capabilities []string `yaml:"capabilities,omitempty"`
+	}
+)
+
+const tagPrefix = "tag:"
+
+// IsEnabled method returns true if the policy has any rule.
+func (p AccessPolicy) IsEnabled() bool {
+	return !p.Allow.IsEmpty() || !p.Deny.IsEmpty()
+}
+
+// IsAllowed method returns true if the identity can access.
+func (p AccessPolicy) IsAllowed(who Whois) bool {
+	if p.Deny.Matches(who) {
+		return false
+	}
+
+	return p.Allow.IsEmpty() || p.Allow.Matches(who)
+}
+
+// IsEmpty method returns true if the rule has no conditions.
+func (r AccessRule) IsEmpty() bool {
+	return len(r.Users) == 0 && len(r.UserIDs) == 0 && len(r.Tags) == 0 && len(r.Nodes) == 0 &&
+		len(r.Capabilities) == 0
+}
+
+// Matches method returns true if any condition of the rule matches the identity.
+func (r AccessRule) Matches(who Whois) bool {
+	if matchAny(r.Users, who.Username) || matchAny(r.Nodes, who.NodeName) {
+		return true
+	}
+
+	if who.ID != "" && slices.Contains(r.UserIDs, who.ID) {
+		return true
+	}
+
+	for _, capability := range r.Capabilities {
+		if who.HasCapability(capability) {
+			return true
+		}
+	}
+
+	for _, tag := range r.Tags {
+		if !strings.HasPrefix(tag, tagPrefix) {
+			tag = tagPrefix + tag
+		}
+
+		for _, nodeTag := range who.Tags {
+			if matchPattern(tag, nodeTag) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// matchAny function returns true if the value matches any of the patterns.
+func matchAny(patterns []string, value string) bool {
+	if value == "" {
+		return false
+	}
+
+	for _, pattern := range patterns {
+		if matchPattern(pattern, value) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchPattern function compares case insensitive with support for wildcards.
+func matchPattern(pattern, value string) bool {
+	pattern = strings.ToLower(pattern)
+	value = strings.ToLower(value)
+
+	if !strings.ContainsAny(pattern, "*?[") {
+		return pattern == value
+	}
+
+	ok, err := path.Match(pattern, value)
+
+	return err == nil && ok
+}
@@ -0,0 +1,70 @@
+// SPDX-FileCopyrightText: 2025 Hector @sudosu404 <hector@email.gnx>
+// SPDX-License-Identifier: AGPL3
+
+package model
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ByteSize is a size in bytes, parsed from values like "512", "100k", "10mb" or "2g".
+// Units are powers of 1024.
+type ByteSize int64
+
+var ErrInvalidByteSize = errors.New("invalid size, expected a number with an optional k, m or g unit")
+
+var byteSizeUnits = []struct {
+	suffix     string
+	multiplier int64
+}{
+	{"kb", 1 << 10},
+	{"mb", 1 << 20},
+	{"gb", 1 << 30},
+	{"k", 1 << 10},
+	{"m", 1 << 20},
+	{"g", 1 << 30},
+	{"b", 1},
+}
+
+// ParseByteSize function parses a size like "2g" into bytes.
+func ParseByteSize(s string) (ByteSize, error) {
+	value := strings.ToLower(strings.TrimSpace(s))
+	multiplier := int64(1)
+
+	for _, unit := range byteSizeUnits {
+		if v, ok := strings.CutSuffix(value, unit.suffix); ok {
+			value = strings.TrimSpace(v)
+			multiplier = unit.multiplier
+			break
+		}
+	}
+
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidByteSize, s)
+	}
+
+	return ByteSize(n * multiplier), nil
+}
+
+// UnmarshalYAML method accepts a number of bytes or a size with unit.
+func (b *ByteSize) UnmarshalYAML(value *yaml.Node) error {
+	size, err := ParseByteSize(value.Value)
+	if err != nil {
+		return err
+	}
+
+	*b = size
+
+	return nil
+}
+
+// MarshalYAML method returns the size in bytes.
+func (b ByteSize) MarshalYAML() (any, error) {
+	return int64(b), nil
+}
@@ -0,0 +1,33 @@
+// SPDX-FileCopyrightText: 2025 Hector @sudosu404 <hector@email.gnx>
+// SPDX-License-Identifier: AGPL3
+
+package model
+
+type (
+	// Canary struct splits the requests of a port between the port targets (stable)
+	// and a canary target. The first match decides: the header, the cookie, the
+	// identities of Match and finally the weight, assigned sticky per user.
+	Canary struct {
+		// Target is the URL of the canary, canary routing is disabled when empty.
+		Target string `yaml:"target,omitempty"`
+		// Weight is the percentage of the users sent to the canary.
+		Weight float64 `validate:"omitempty,min=0,max=100" yaml:"weight,omitempty"`
+		// Header selects the target with the values "canary" or "stable".
+		Header string `yaml:"header,omitempty"`
+		// Cookie selects the target with the values "canary" or "stable".
+		Cookie string `yaml:"cookie,omitempty"`
+		// Match are the identities always sent to the canary.
+		Match AccessRule `validate:"dive" yaml:"match,omitempty"`
+	}
+)
+
+const (
+	// Values of the canary header and cookie.
+	CanaryValueCanary = "canary"
+	CanaryValueStable = "stable"
+)
+
+// IsEnabled method returns true if the port has a canary target.
+func (c *Canary) IsEnabled() bool {
+	return c.Target != ""
+}
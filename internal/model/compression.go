@@ -0,0 +1,79 @@
+// SPDX-FileCopyrightText: 2025 Hector @sudosu404 <hector@email.gnx>
+// SPDX-License-Identifier: AGPL3
+
+package model
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+type (
+	// Compression struct stores the response compression configuration of a port.
+	Compression struct {
+		Enabled bool `validate:"boolean" yaml:"enabled,omitempty"`
+		// Algorithms in order of preference, defaults to zstd, br and gzip.
+		Algorithms []string `yaml:"algorithms,omitempty"`
+		// MinSize is the smallest response compressed, defaults to 1k.
+		MinSize ByteSize `yaml:"minSize,omitempty"`
+		// SkipTypes are content types not compressed, in addition to the already
+		// compressed types like images, videos and archives. A type ending with "/"
+		// matches all its subtypes.
+		SkipTypes []string `yaml:"skipTypes,omitempty"`
+	}
+)
+
+const (
+	CompressionZstd   = "zstd"
+	CompressionBrotli = "br"
+	CompressionGzip   = "gzip"
+
+	DefaultCompressionMinSize ByteSize = 1024
+)
+
+var (
+	ErrInvalidCompression = errors.New("invalid compression algorithm, expected zstd, br or gzip")
+
+	defaultCompressionAlgorithms = []string{CompressionZstd, CompressionBrotli, CompressionGzip}
+)
+
+// ParseCompressionAlgorithms function parses a list of algorithms separated by "|" or spaces.
+func ParseCompressionAlgorithms(s string) ([]string, error) {
+	fields := strings.FieldsFunc(s, func(r rune) bool { return r == '|' || r == ' ' })
+
+	algorithms := make([]string, 0, len(fields))
+	for _, f := range fields {
+		f = strings.ToLower(f)
+		if !slices.Contains(defaultCompressionAlgorithms, f) {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidCompression, f)
+		}
+		algorithms = append(algorithms, f)
+	}
+
+	return algorithms, nil
+}
+
+// GetAlgorithms method returns the configured algorithms supported by Tailnet.
+func (c *Compression) GetAlgorithms() []string {
+	if len(c.Algorithms) == 0 {
+		return defaultCompressionAlgorithms
+	}
+
+	algorithms := make([]string, 0, len(c.Algorithms))
+	for _, a := range c.Algorithms {
+		if a = strings.ToLower(a); slices.Contains(defaultCompressionAlgorithms, a) {
+			algorithms = append(algorithms, a)
+		}
+	}
+
+	return algorithms
+}
+
+func (c *Compression) GetMinSize() ByteSize {
+	if c.MinSize <= 0 {
+		return DefaultCompressionMinSize
+	}
+	return c.MinSize
+}
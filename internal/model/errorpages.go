@@ -0,0 +1,62 @@
+// SPDX-FileCopyrightText: 2025 Hector @sudosu404 <hector@email.gnx>
+// SPDX-License-Identifier: AGPL3
+
+package model
+
+import (
+	"time"
+)
+
+type (
+	// ErrorPages struct customizes the error pages served by the http ports of a proxy.
+	ErrorPages struct {
+		// Brand is shown in the footer of the pages (defaults to Tailnet).
+		Brand string `yaml:"brand,omitempty"`
+		// Messages replace the default message of a status code,
+		// ex: 502: "The service is starting, try again in a minute."
+		Messages map[int]string `yaml:"messages,omitempty"`
+	}
+
+	// Maintenance struct stores the maintenance mode of a proxy, the http ports
+	// answer 503 with the maintenance page while it's enabled.
+	Maintenance struct {
+		Enabled    bool          `validate:"boolean" yaml:"enabled"`
+		Message    string        `yaml:"message,omitempty"`
+		RetryAfter time.Duration `yaml:"retryAfter,omitempty"`
+	}
+)
+
+const (
+	DefaultErrorPagesBrand       = "Tailnet"
+	DefaultMaintenanceMessage    = "This service is under maintenance, please try again later."
+	DefaultMaintenanceRetryAfter = 5 * time.Minute
+)
+
+func (e *ErrorPages) GetBrand() string {
+	if e.Brand == "" {
+		return DefaultErrorPagesBrand
+	}
+	return e.Brand
+}
+
+// GetMessage method returns the custom message of a status code or defaultMessage.
+func (e *ErrorPages) GetMessage(statusCode int, defaultMessage string) string {
+	if msg, ok := e.Messages[statusCode]; ok && msg != "" {
+		return msg
+	}
+	return defaultMessage
+}
+
+func (m *Maintenance) GetMessage() string {
+	if m.Message == "" {
+		return DefaultMaintenanceMessage
+	}
+	return m.Message
+}
+
+func (m *Maintenance) GetRetryAfter() time.Duration {
+	if m.RetryAfter <= 0 {
+		return DefaultMaintenanceRetryAfter
+	}
+	return m.RetryAfter
+}
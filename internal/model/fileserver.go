@@ -0,0 +1,24 @@
+// SPDX-FileCopyrightText: 2025 Hector @sudosu404 <hector@email.gnx>
+// SPDX-License-Identifier: AGPL3
+
+package model
+
+type (
+	// FileServer struct stores the options of the ports with a file:// target.
+	FileServer struct {
+		// Listing shows the content of the directories without an index file.
+		Listing bool `yaml:"listing,omitempty"`
+		// IndexFiles are served for the directories, the first existing file is used.
+		IndexFiles []string `yaml:"indexFiles,omitempty"`
+	}
+)
+
+// DefaultIndexFiles are served for the directories when no index files are configured.
+var DefaultIndexFiles = []string{"index.html"}
+
+func (f *FileServer) GetIndexFiles() []string {
+	if len(f.IndexFiles) == 0 {
+		return DefaultIndexFiles
+	}
+	return f.IndexFiles
+}
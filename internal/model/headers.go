@@ -0,0 +1,98 @@
+// SPDX-FileCopyrightText: 2025 Hector @sudosu404 <hector@email.gnx>
+// SPDX-License-Identifier: AGPL3
+
+package model
+
+import (
+	"time"
+
+	"github.com/sudosu404/tailnet-lib/internal/consts"
+)
+
+type (
+	// Headers struct stores the header rewrite rules of a port.
+	Headers struct {
+		// Request rules are applied to the requests sent to the targets.
+		Request HeaderRules `validate:"dive" yaml:"request,omitempty"`
+		// Response rules are applied to the responses sent to the clients.
+		Response HeaderRules `validate:"dive" yaml:"response,omitempty"`
+		// Identity configures the headers with the Tailscale identity of the client.
+		Identity IdentityHeaders `validate:"dive" yaml:"identity,omitempty"`
+	}
+
+	// HeaderRules struct stores the headers to remove, set and add, in this order.
+	// Set and Add values are templates like "{{.Whois.Username}}" or "{{.Proxy.Hostname}}".
+	HeaderRules struct {
+		Set    map[string]string `yaml:"set,omitempty"`
+		Add    map[string]string `yaml:"add,omitempty"`
+		Remove []string          `yaml:"remove,omitempty"`
+	}
+
+	// IdentityHeaders struct renames or disables the identity headers.
+	IdentityHeaders struct {
+		Disabled      bool   `validate:"boolean" yaml:"disabled,omitempty"`
+		Username      string `yaml:"username,omitempty"`
+		DisplayName   string `yaml:"displayName,omitempty"`
+		ProfilePicURL string `yaml:"profilePicUrl,omitempty"`
+		// Capabilities header has the JSON of the app capabilities of the client.
+		Capabilities string `yaml:"capabilities,omitempty"`
+	}
+
+	// IdentityToken struct configures the signed JWT with the Tailscale identity
+	// of the client, minted on each request.
+	IdentityToken struct {
+		Enabled  bool          `validate:"boolean" yaml:"enabled"`
+		Header   string        `yaml:"header,omitempty"`
+		Audience string        `yaml:"audience,omitempty"`
+		TTL      time.Duration `yaml:"ttl,omitempty"`
+	}
+)
+
+const DefaultIdentityTokenTTL = time.Minute
+
+// IsEmpty method returns true if there are no rules.
+func (h *HeaderRules) IsEmpty() bool {
+	return len(h.Set) == 0 && len(h.Add) == 0 && len(h.Remove) == 0
+}
+
+func (h *IdentityHeaders) GetUsername() string {
+	if h.Username == "" {
+		return consts.HeaderUsername
+	}
+	return h.Username
+}
+
+func (h *IdentityHeaders) GetDisplayName() string {
+	if h.DisplayName == "" {
+		return consts.HeaderDisplayName
+	}
+	return h.DisplayName
+}
+
+func (h *IdentityHeaders) GetProfilePicURL() string {
+	if h.ProfilePicURL == "" {
+		return consts.HeaderProfilePicURL
+	}
+	return h.ProfilePicURL
+}
+
+func (h *IdentityHeaders) GetCapabilities() string {
+	if h.Capabilities == "" {
+		return consts.HeaderCapabilities
+	}
+	return h.Capabilities
+}
+
+func (t *IdentityToken) GetHeader() string {
+	if t.Header == "" {
+		return consts.HeaderIdentityToken
+	}
+	return t.Header
+}
+
+func (t *IdentityToken) GetTTL() time.Duration {
+	if t.TTL <= 0 {
+		return DefaultIdentityTokenTTL
+	}
+	return t.TTL
+}
@@ -0,0 +1,93 @@
+// SPDX-FileCopyrightText: 2025 Hector @sudosu404 <hector@email.gnx>
+// SPDX-License-Identifier: AGPL3
+
+package model
+
+import (
+	"strings"
+	"time"
+)
+
+type (
+	// HealthCheck struct stores the active health check configuration of a port.
+	HealthCheck struct {
+		// Type is "http" or "tcp", empty disables the health check.
+		Type               string        `validate:"omitempty,oneof=http tcp" yaml:"type,omitempty"`
+		Path               string        `yaml:"path,omitempty"`
+		Interval           time.Duration `yaml:"interval,omitempty"`
+		Timeout            time.Duration `yaml:"timeout,omitempty"`
+		HealthyThreshold   int           `yaml:"healthyThreshold,omitempty"`
+		UnhealthyThreshold int           `yaml:"unhealthyThreshold,omitempty"`
+	}
+)
+
+const (
+	HealthCheckHTTP = "http"
+	HealthCheckTCP  = "tcp"
+
+	DefaultHealthCheckPath               = "/"
+	DefaultHealthCheckInterval           = 10 * time.Second
+	DefaultHealthCheckTimeout            = 2 * time.Second
+	DefaultHealthCheckHealthyThreshold   = 2
+	DefaultHealthCheckUnhealthyThreshold = 3
+)
+
+// NewHealthCheck function returns a HealthCheck from a short definition:
+// "tcp" for a tcp dial or a path like "/healthz" for an http check.
+func NewHealthCheck(s string) HealthCheck {
+	s = strings.TrimSpace(s)
+
+	switch {
+	case s == "":
+		return HealthCheck{}
+	case strings.EqualFold(s, HealthCheckTCP):
+		return HealthCheck{Type: HealthCheckTCP}
+	case strings.EqualFold(s, HealthCheckHTTP):
+		return HealthCheck{Type: HealthCheckHTTP}
+	default:
+		return HealthCheck{Type: HealthCheckHTTP, Path: s}
+	}
+}
+
+// IsEnabled method returns true if the health check is configured.
+func (h *HealthCheck) IsEnabled() bool {
+	return h.Type != ""
+}
+
+func (h *HealthCheck) GetPath() string {
+	if h.Path == "" {
+		return DefaultHealthCheckPath
+	}
+	if !strings.HasPrefix(h.Path, "/") {
+		return "/" + h.Path
+	}
+	return h.Path
+}
+
+func (h *HealthCheck) GetInterval() time.Duration {
+	if h.Interval <= 0 {
+		return DefaultHealthCheckInterval
+	}
+	return h.Interval
+}
+
+func (h *HealthCheck) GetTimeout() time.Duration {
+	if h.Timeout <= 0 {
+		return DefaultHealthCheckTimeout
+	}
+	return h.Timeout
+}
+
+func (h *HealthCheck) GetHealthyThreshold() int {
+	if h.HealthyThreshold <= 0 {
+		return DefaultHealthCheckHealthyThreshold
+	}
+	return h.HealthyThreshold
+}
+
+func (h *HealthCheck) GetUnhealthyThreshold() int {
+	if h.UnhealthyThreshold <= 0 {
+		return DefaultHealthCheckUnhealthyThreshold
+	}
+	return h.UnhealthyThreshold
+}
@@ -0,0 +1,111 @@
+// SPDX-FileCopyrightText: 2025 Hector @sudosu404 <hector@email.gnx>
+// SPDX-License-Identifier: AGPL3
+
+package model
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type (
+	// LoadBalancerStrategy defines how requests are distributed across the targets of a port.
+	LoadBalancerStrategy string
+
+	// LoadBalancer struct stores the load balancing configuration of a port.
+	LoadBalancer struct {
+		Strategy LoadBalancerStrategy `validate:"omitempty,oneof=round_robin random least_connections weighted" yaml:"strategy,omitempty"`
+		// Weights are used by the weighted strategy, one per target in the same order.
+		Weights []int `yaml:"weights,omitempty"`
+		// Affinity sends the requests of a client to the same target while it's healthy.
+		Affinity SessionAffinity `validate:"dive" yaml:"affinity,omitempty"`
+	}
+
+	// SessionAffinityType defines how the clients are bound to a target.
+	SessionAffinityType string
+
+	// SessionAffinity struct stores the sticky sessions configuration of a port.
+	SessionAffinity struct {
+		Type SessionAffinityType `validate:"omitempty,oneof=cookie node ip" yaml:"type,omitempty"`
+		// Cookie is the name of the cookie set by tailnet with the cookie type.
+		Cookie string `yaml:"cookie,omitempty"`
+		// CookieTTL is the lifetime of the cookie, it expires with the browser session by default.
+		CookieTTL time.Duration `yaml:"cookieTTL,omitempty"`
+	}
+)
+
+const (
+	LoadBalancerRoundRobin       LoadBalancerStrategy = "round_robin"
+	LoadBalancerRandom           LoadBalancerStrategy = "random"
+	LoadBalancerLeastConnections LoadBalancerStrategy = "least_connections"
+	LoadBalancerWeighted         LoadBalancerStrategy = "weighted"
+
+	DefaultLoadBalancerStrategy = LoadBalancerRoundRobin
+
+	// SessionAffinityCookie binds the clients with a cookie set by tailnet.
+	SessionAffinityCookie SessionAffinityType = "cookie"
+	// SessionAffinityNode binds the clients by a hash of the Tailscale node ID.
+	SessionAffinityNode SessionAffinityType = "node"
+	// SessionAffinityIP binds the clients by a hash of the source IP.
+	SessionAffinityIP SessionAffinityType = "ip"
+
+	DefaultAffinityCookie = "tailnet_affinity"
+)
+
+// ParseLoadBalancerStrategy returns the LoadBalancerStrategy for a string.
+func ParseLoadBalancerStrategy(s string) (LoadBalancerStrategy, error) {
+	strategy := LoadBalancerStrategy(strings.ToLower(strings.TrimSpace(s)))
+
+	switch strategy {
+	case LoadBalancerRoundRobin, LoadBalancerRandom, LoadBalancerLeastConnections, LoadBalancerWeighted:
+		return strategy, nil
+	case "":
+		return DefaultLoadBalancerStrategy, nil
+	}
+
+	return "", fmt.Errorf("invalid load balancer strategy: %s", s)
+}
+
+// GetStrategy returns the configured strategy or the default one.
+func (lb *LoadBalancer) GetStrategy() LoadBalancerStrategy {
+	if lb.Strategy == "" {
+		return DefaultLoadBalancerStrategy
+	}
+	return lb.Strategy
+}
+
+// GetWeight returns the weight of the target at index i, defaults to 1.
+func (lb *LoadBalancer) GetWeight(i int) int {
+	if i < len(lb.Weights) && lb.Weights[i] > 0 {
+		return lb.Weights[i]
+	}
+	return 1
+}
+
+// ParseSessionAffinityType returns the SessionAffinityType for a string.
+func ParseSessionAffinityType(s string) (SessionAffinityType, error) {
+	affinity := SessionAffinityType(strings.ToLower(strings.TrimSpace(s)))
+
+	switch affinity {
+	case SessionAffinityCookie, SessionAffinityNode, SessionAffinityIP:
+		return affinity, nil
+	}
+
+	return "", fmt.Errorf("invalid session affinity: %s", s)
+}
+
+// IsEnabled method returns true if the port uses sticky sessions.
+func (a *SessionAffinity) IsEnabled() bool {
+	return a.Type != ""
+}
+
+// GetCookie returns the name of the affinity cookie, defaults to tailnet_affinity_<port>
+// so the ports of a hostname don't share the cookie.
+func (a *SessionAffinity) GetCookie(port int) string {
+	if a.Cookie == "" {
+		return DefaultAffinityCookie + "_" + strconv.Itoa(port)
+	}
+	return a.Cookie
+}
@@ -0,0 +1,76 @@
+// SPDX-FileCopyrightText: 2025 Hector @sudosu404 <hector@email.gnx>
+// SPDX-License-Identifier: AGPL3
+
+package model
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type (
+	// Mirror struct stores the shadow target that receives a copy of the port requests.
+	// The responses of the mirror are discarded.
+	Mirror struct {
+		// Target is the URL of the mirror, mirroring is disabled when empty.
+		Target string `yaml:"target,omitempty"`
+		// Percent of the requests mirrored, defaults to 100, zero disables mirroring.
+		Percent *float64 `validate:"omitempty,min=0,max=100" yaml:"percent,omitempty"`
+		// Timeout of the mirrored requests, defaults to 10s.
+		Timeout time.Duration `yaml:"timeout,omitempty"`
+		// MaxBodySize of the mirrored requests, requests with larger bodies are not mirrored.
+		MaxBodySize ByteSize `yaml:"maxBodySize,omitempty"`
+	}
+)
+
+const (
+	DefaultMirrorTimeout     = 10 * time.Second
+	DefaultMirrorMaxBodySize = ByteSize(1 << 20)
+)
+
+var ErrInvalidPercent = errors.New("invalid percent, expected a number between 0 and 100")
+
+// ParsePercent function parses a percentage like "10" or "12.5%".
+func ParsePercent(s string) (float64, error) {
+	percent, err := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(s), "%"), 64)
+	if err != nil || !IsValidPercent(percent) {
+		return 0, fmt.Errorf("%w: %s", ErrInvalidPercent, s)
+	}
+
+	return percent, nil
+}
+
+// IsValidPercent function returns true for numbers between 0 and 100, NaN isn't valid.
+func IsValidPercent(percent float64) bool {
+	return !math.IsNaN(percent) && percent >= 0 && percent <= 100
+}
+
+// IsEnabled method returns true if the port has a mirror target.
+func (m *Mirror) IsEnabled() bool {
+	return m.Target != ""
+}
+
+func (m *Mirror) GetPercent() float64 {
+	if m.Percent == nil {
+		return 100 //nolint:mnd
+	}
+	return *m.Percent
+}
+
+func (m *Mirror) GetTimeout() time.Duration {
+	if m.Timeout <= 0 {
+		return DefaultMirrorTimeout
+	}
+	return m.Timeout
+}
+
+func (m *Mirror) GetMaxBodySize() int64 {
+	if m.MaxBodySize <= 0 {
+		return int64(DefaultMirrorMaxBodySize)
+	}
+	return int64(m.MaxBodySize)
+}
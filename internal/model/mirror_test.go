@@ -0,0 +1,67 @@
+// SPDX-FileCopyrightText: 2025 Hector @sudosu404 <hector@email.gnx>
+// SPDX-License-Identifier: AGPL3
+
+package model
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParsePercent(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    float64
+		wantErr bool
+	}{
+		{value: "10", want: 10},
+		{value: " 12.5% ", want: 12.5},
+		{value: "0", want: 0},
+		{value: "100", want: 100},
+		{value: "-1", wantErr: true},
+		{value: "100.1", wantErr: true},
+		{value: "NaN", wantErr: true},
+		{value: "Inf", wantErr: true},
+		{value: "ten", wantErr: true},
+		{value: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParsePercent(tt.value)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidPercent) {
+					t.Errorf("got %v, %v, want ErrInvalidPercent", got, err)
+				}
+				return
+			}
+
+			if err != nil || got != tt.want {
+				t.Errorf("got %v, %v, want %v", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestMirrorGetPercent(t *testing.T) {
+	zero, ten := 0.0, 10.0
+
+	tests := []struct {
+		name    string
+		percent *float64
+		want    float64
+	}{
+		{name: "unset", percent: nil, want: 100},
+		{name: "zero", percent: &zero, want: 0},
+		{name: "ten", percent: &ten, want: 10},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := Mirror{Percent: tt.percent}
+			if got := m.GetPercent(); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
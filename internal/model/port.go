@@ -9,6 +9,7 @@ import (
 	"net/url"
 	"strconv"
 	"strings"
+	"time"
 )
 
 type (
@@ -20,6 +21,61 @@ type (
 		TLSValidate   bool          `validate:"boolean" yaml:"tlsValidate"`
 		IsRedirect    bool          `validate:"boolean" yaml:"isRedirect"`
 		Tailscale     TailscalePort `validate:"dive" yaml:"tailscale"`
+		LoadBalancer  LoadBalancer  `validate:"dive" yaml:"loadBalancer"`
+		HealthCheck   HealthCheck   `validate:"dive" yaml:"healthCheck"`
+		Timeouts      PortTimeouts  `validate:"dive" yaml:"timeouts"`
+		// SNIRoutes maps TLS server names to targets on tls passthrough ports.
+		SNIRoutes map[string]*url.URL `yaml:"-"`
+		// Routes are evaluated in order, requests without a match use the port targets.
+		Routes []Route `validate:"dive" yaml:"routes"`
+		// AccessPolicy is checked after the proxy access policy.
+		AccessPolicy AccessPolicy `validate:"dive" yaml:"accessPolicy"`
+		RateLimits   RateLimits   `validate:"dive" yaml:"rateLimits"`
+		Headers      Headers      `validate:"dive" yaml:"headers"`
+		// IdentityToken forwards a signed JWT with the client identity.
+		IdentityToken IdentityToken `validate:"dive" yaml:"identityToken"`
+		// MaxBodySize limits the request body, larger requests get 413.
+		MaxBodySize ByteSize `yaml:"maxBodySize"`
+		// UpstreamTLS configures the TLS connections to https targets.
+		UpstreamTLS UpstreamTLS `validate:"dive" yaml:"upstreamTLS"`
+		// SendProxyProtocol sends a PROXY protocol header, "v1" or "v2", on the target connections.
+		SendProxyProtocol string      `validate:"omitempty,oneof=v1 v2" yaml:"sendProxyProtocol"`
+		Compression       Compression `validate:"dive" yaml:"compression"`
+		// Redirect configures the redirect ports.
+		Redirect Redirect `validate:"dive" yaml:"redirect"`
+		// Files configures the ports that serve a local directory.
+		Files FileServer `validate:"dive" yaml:"files"`
+		// Mirror sends a copy of the requests to a shadow target.
+		Mirror Mirror `validate:"dive" yaml:"mirror"`
+		// Canary sends part of the requests to a canary target.
+		Canary Canary `validate:"dive" yaml:"canary"`
+	}
+
+	// PortTimeouts struct stores the timeouts of a port, zero values disable them.
+	PortTimeouts struct {
+		// Idle closes tcp and udp passthrough connections without traffic,
+		// and the idle keep-alive connections of http ports.
+		Idle time.Duration `yaml:"idle,omitempty"`
+		// Dial limits the time to connect to the target.
+		Dial time.Duration `yaml:"dial,omitempty"`
+		// ResponseHeader limits the time to receive the response headers of the target.
+		ResponseHeader time.Duration `yaml:"responseHeader,omitempty"`
+		// Read limits the time to receive the request body, exceeded requests get 408.
+		Read time.Duration `yaml:"read,omitempty"`
+		// Request limits the overall time of a request, exceeded requests get 504.
+		Request time.Duration `yaml:"request,omitempty"`
+	}
+
+	// UpstreamTLS struct stores the TLS settings of the connections to the targets.
+	// The files are PEM encoded.
+	UpstreamTLS struct {
+		// CAFile is a CA bundle trusted in addition to the system roots.
+		CAFile string `yaml:"caFile,omitempty"`
+		// ServerName is verified instead of the target hostname.
+		ServerName string `yaml:"serverName,omitempty"`
+		// CertFile and KeyFile are the client certificate for mutual TLS.
+		CertFile string `yaml:"certFile,omitempty"`
+		KeyFile  string `yaml:"keyFile,omitempty"`
 	}
 
 	TailscalePort struct {
@@ -28,6 +84,24 @@ type (
 )
 
 const (
+	ProtocolTCP = "tcp"
+	ProtocolUDP = "udp"
+	ProtocolTLS = "tls"
+
+	// PROXY protocol versions sent to the targets.
+	ProxyProtocolV1 = "v1"
+	ProxyProtocolV2 = "v2"
+
+	// HTTP/2 target schemes, h2c and grpc without TLS and grpcs with TLS.
+	SchemeH2C   = "h2c"
+	SchemeGRPC  = "grpc"
+	SchemeGRPCS = "grpcs"
+
+	// SchemeFile targets serve a local directory, like file:///srv/docs.
+	SchemeFile = "file"
+
+	DefaultUDPIdleTimeout = time.Minute
+
 	redirectSeparator = "->"
 	proxySeparator    = ":"
 	protocolSeparator = "/"
@@ -37,6 +111,10 @@ var (
 	ErrInvalidPortFormat   = errors.New("invalid format, missing '" + protocolSeparator + "' or '" + redirectSeparator + "'")
 	ErrInvalidProxyConfig  = errors.New("invalid proxy configuration")
 	ErrInvalidTargetConfig = errors.New("invalid target configuration")
+	ErrFunnelTLS           = errors.New("funnel terminates TLS on the node, it can't be used on tls passthrough ports")
+	ErrFunnelUDP           = errors.New("funnel only forwards tcp traffic, it can't be used on udp ports")
+	ErrHealthCheckHTTP     = errors.New("http health checks can't be used on tcp or tls ports, use a tcp health check")
+	ErrHealthCheckUDP      = errors.New("health checks can't be used on udp ports")
 )
 
 // NewPortLongLabel parses a port configuration string and returns a PortConfig struct.
@@ -49,9 +127,15 @@ var (
 //   - Example: "443:80"
 //   - Defaults: "https" for `proxy protocol` and "http" for `target protocol`.
 //
-// 3. "<proxy port>/<proxy protocol>-><target URL>"
+// 3. "<proxy port>/tcp:<target port>", "<proxy port>/udp:<target port>" or "<proxy port>/tls:<target port>"
+//   - Example: "5432/tcp:5432"
+//   - Defaults: the proxy protocol for `target protocol` ("tcp" for tls), traffic is forwarded without inspection.
+//   - tls forwards the encrypted stream to the target, which terminates TLS itself.
+//
+// 4. "<proxy port>/<proxy protocol>-><target URL>"
 //   - Example: "443/https->https://example.com"
 //   - This format indicates a redirect, setting `IsRedirect` to true and TargetURL.
+//   - "80/http->https" redirects to https on the same hostname.
 //
 // Returns:
 // - PortConfig: A struct containing parsed proxy and target configurations.
@@ -60,7 +144,8 @@ var (
 // Examples:
 // 1. "443/https:80/http" -> ProxyPort=443, ProxyProtocol="https", TargetPort=80, TargetProtocol="http"
 // 2. "443:80" -> ProxyPort=443, ProxyProtocol="https", TargetPort=80, TargetProtocol="http"
-// 3. "443/https->https://example.com" -> ProxyPort=443, ProxyProtocol="https", IsRedirect=true, TargetURL=https://example.com
+// 3. "5432/tcp:5432" -> ProxyPort=5432, ProxyProtocol="tcp", TargetPort=5432, TargetProtocol="tcp"
+// 4. "443/https->https://example.com" -> ProxyPort=443, ProxyProtocol="https", IsRedirect=true, TargetURL=https://example.com
 
 func NewPortLongLabel(s string) (PortConfig, error) {
 	config := defaultPortConfig(s)
@@ -157,6 +242,12 @@ func parseTargetSegment(segment string, config *PortConfig) error {
 	}
 
 	targetProtocol := "http"
+	switch config.ProxyProtocol {
+	case ProtocolTCP, ProtocolUDP:
+		targetProtocol = config.ProxyProtocol
+	case ProtocolTLS:
+		targetProtocol = ProtocolTCP
+	}
 
 	if len(targetParts) == 2 { //nolint:mnd
 		targetProtocol = targetParts[1]
@@ -173,6 +264,11 @@ func parseTargetSegment(segment string, config *PortConfig) error {
 }
 
 func parseRedirectTarget(segment string, config *PortConfig) error {
+	if strings.EqualFold(strings.TrimSpace(segment), RedirectHTTPS) {
+		config.Redirect.HTTPS = true
+		return nil
+	}
+
 	targetURL, err := url.Parse(segment)
 	if err != nil || targetURL.Scheme == "" || targetURL.Host == "" {
 		return fmt.Errorf("invalid target URL: %v", segment)
@@ -183,6 +279,62 @@ func parseRedirectTarget(segment string, config *PortConfig) error {
 	return nil
 }
 
+// IsPassthrough method returns true if the port forwards raw tcp, udp or tls traffic.
+func (p *PortConfig) IsPassthrough() bool {
+	switch p.ProxyProtocol {
+	case ProtocolTCP, ProtocolUDP, ProtocolTLS:
+		return true
+	}
+	return false
+}
+
+// Validate method returns an error if the options of the port can't be combined.
+func (p *PortConfig) Validate() error {
+	if p.Tailscale.Funnel && p.ProxyProtocol == ProtocolTLS {
+		return ErrFunnelTLS
+	}
+	if p.Tailscale.Funnel && p.ProxyProtocol == ProtocolUDP {
+		return ErrFunnelUDP
+	}
+	if p.HealthCheck.IsEnabled() {
+		switch {
+		case p.ProxyProtocol == ProtocolUDP:
+			return ErrHealthCheckUDP
+		case p.IsPassthrough() && p.HealthCheck.Type != HealthCheckTCP:
+			return ErrHealthCheckHTTP
+		}
+	}
+	if p.Mirror.Percent != nil && !IsValidPercent(*p.Mirror.Percent) {
+		return fmt.Errorf("%w: mirror percent %v", ErrInvalidPercent, *p.Mirror.Percent)
+	}
+	if !IsValidPercent(p.Canary.Weight) {
+		return fmt.Errorf("%w: canary weight %v", ErrInvalidPercent, p.Canary.Weight)
+	}
+	return nil
+}
+
+// IsFileServer method returns true if the port serves a local directory, with a file:// target.
+func (p *PortConfig) IsFileServer() bool {
+	return len(p.targets) > 0 && p.targets[0].Scheme == SchemeFile
+}
+
+// IsHTTP2Scheme function returns true if the target scheme is sent with HTTP/2.
+func IsHTTP2Scheme(scheme string) bool {
+	switch scheme {
+	case SchemeH2C, SchemeGRPC, SchemeGRPCS:
+		return true
+	}
+	return false
+}
+
+// GetIdleTimeout method returns the idle timeout of passthrough connections.
+func (p *PortConfig) GetIdleTimeout() time.Duration {
+	if p.Timeouts.Idle <= 0 && p.ProxyProtocol == ProtocolUDP {
+		return DefaultUDPIdleTimeout
+	}
+	return p.Timeouts.Idle
+}
+
 func (p *PortConfig) GetTargets() []*url.URL {
 	return p.targets
 }
@@ -198,6 +350,14 @@ func (p *PortConfig) AddTarget(target *url.URL) {
 	p.targets = append(p.targets, target)
 }
 
+// AddSNIRoute method routes a TLS server name to a target.
+func (p *PortConfig) AddSNIRoute(serverName string, target *url.URL) {
+	if p.SNIRoutes == nil {
+		p.SNIRoutes = make(map[string]*url.URL)
+	}
+	p.SNIRoutes[strings.ToLower(serverName)] = target
+}
+
 // ReplaceTarget replaces a target URL with a new one.
 // used mainly for updating the target URL when the container IP changes like docker provider.
 func (p *PortConfig) ReplaceTarget(origin, target *url.URL) {
@@ -0,0 +1,78 @@
+// SPDX-FileCopyrightText: 2025 Hector @sudosu404 <hector@email.gnx>
+// SPDX-License-Identifier: AGPL3
+
+package model
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestPortConfigValidatePercent(t *testing.T) {
+	nan := math.NaN()
+
+	tests := []struct {
+		name string
+		port PortConfig
+	}{
+		{name: "mirror NaN", port: PortConfig{Mirror: Mirror{Percent: &nan}}},
+		{name: "canary NaN", port: PortConfig{Canary: Canary{Weight: nan}}},
+		{name: "canary over 100", port: PortConfig{Canary: Canary{Weight: 101}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.port.Validate(); !errors.Is(err, ErrInvalidPercent) {
+				t.Errorf("got %v, want ErrInvalidPercent", err)
+			}
+		})
+	}
+}
+
+func TestPortConfigValidateHealthCheck(t *testing.T) {
+	tests := []struct {
+		name     string
+		protocol string
+		check    string
+		want     error
+	}{
+		{name: "http on https", protocol: "https", check: HealthCheckHTTP},
+		{name: "tcp on tcp", protocol: ProtocolTCP, check: HealthCheckTCP},
+		{name: "tcp on tls", protocol: ProtocolTLS, check: HealthCheckTCP},
+		{name: "http on tcp", protocol: ProtocolTCP, check: HealthCheckHTTP, want: ErrHealthCheckHTTP},
+		{name: "http on tls", protocol: ProtocolTLS, check: HealthCheckHTTP, want: ErrHealthCheckHTTP},
+		{name: "tcp on udp", protocol: ProtocolUDP, check: HealthCheckTCP, want: ErrHealthCheckUDP},
+		{name: "none on udp", protocol: ProtocolUDP},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			port := PortConfig{ProxyProtocol: tt.protocol, HealthCheck: HealthCheck{Type: tt.check}}
+			if err := port.Validate(); !errors.Is(err, tt.want) {
+				t.Errorf("got %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestPortConfigValidateFunnel(t *testing.T) {
+	tests := []struct {
+		protocol string
+		want     error
+	}{
+		{protocol: "https"},
+		{protocol: ProtocolTCP},
+		{protocol: ProtocolTLS, want: ErrFunnelTLS},
+		{protocol: ProtocolUDP, want: ErrFunnelUDP},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.protocol, func(t *testing.T) {
+			port := PortConfig{ProxyProtocol: tt.protocol, Tailscale: TailscalePort{Funnel: true}}
+			if err := port.Validate(); !errors.Is(err, tt.want) {
+				t.Errorf("got %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
@@ -20,6 +20,13 @@ type (
 		Dashboard      Dashboard `validate:"dive"`
 		Tailscale      Tailscale `validate:"dive"`
 		ProxyAccessLog bool      `default:"true" validate:"boolean"`
+		// AccessLog overrides the access log destination of tailnet.yaml.
+		AccessLog AccessLog `validate:"dive"`
+		// AccessPolicy applies to all http ports of the proxy.
+		AccessPolicy AccessPolicy `validate:"dive"`
+		ErrorPages   ErrorPages
+		// Maintenance is the initial maintenance mode, it can be changed at runtime.
+		Maintenance Maintenance `validate:"dive"`
 	}
 
 	// Tailscale struct stores the configuration for tailscale ProxyProvider
@@ -0,0 +1,104 @@
+// SPDX-FileCopyrightText: 2025 Hector @sudosu404 <hector@email.gnx>
+// SPDX-License-Identifier: AGPL3
+
+package model
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type (
+	// RateLimits struct stores the request limits of a port by scope.
+	RateLimits struct {
+		// PerUser limits each Tailscale user, tagged devices are limited by node.
+		PerUser RateLimit `validate:"dive" yaml:"perUser,omitempty"`
+		// PerNode limits each Tailscale node.
+		PerNode RateLimit `validate:"dive" yaml:"perNode,omitempty"`
+		// PerClientIP limits each client address, used for anonymous funnel traffic.
+		PerClientIP RateLimit `validate:"dive" yaml:"perClientIP,omitempty"`
+		// Global limits all the requests of the port.
+		Global RateLimit `validate:"dive" yaml:"global,omitempty"`
+	}
+
+	// RateLimit struct stores a token bucket rate and a max concurrent requests limit.
+	RateLimit struct {
+		// Requests allowed in each Period, zero disables the rate limit.
+		Requests int           `yaml:"requests,omitempty"`
+		Period   time.Duration `yaml:"period,omitempty"`
+		// Burst defaults to Requests.
+		Burst int `yaml:"burst,omitempty"`
+		// MaxConcurrent requests being served, zero disables the concurrency limit.
+		MaxConcurrent int `yaml:"maxConcurrent,omitempty"`
+	}
+)
+
+const (
+	DefaultRateLimitPeriod = time.Second
+
+	rateSeparator = "/"
+)
+
+var ErrInvalidRateLimit = errors.New("invalid rate limit, expected <requests>/<period>")
+
+// ParseRate function parses a rate like "100/1m", "100/m" or "10" (per second)
+// and sets the Requests and Period of the limit.
+func (l *RateLimit) ParseRate(s string) error {
+	requests, period, hasPeriod := strings.Cut(strings.TrimSpace(s), rateSeparator)
+
+	n, err := strconv.Atoi(strings.TrimSpace(requests))
+	if err != nil || n < 0 {
+		return fmt.Errorf("%w: %s", ErrInvalidRateLimit, s)
+	}
+
+	d := DefaultRateLimitPeriod
+	if hasPeriod {
+		period = strings.TrimSpace(period)
+		// allow units without value like "m"
+		if period != "" && (period[0] < '0' || period[0] > '9') {
+			period = "1" + period
+		}
+
+		d, err = time.ParseDuration(period)
+		if err != nil || d <= 0 {
+			return fmt.Errorf("%w: %s", ErrInvalidRateLimit, s)
+		}
+	}
+
+	l.Requests = n
+	l.Period = d
+
+	return nil
+}
+
+// IsEnabled method returns true if any scope has a limit.
+func (l *RateLimits) IsEnabled() bool {
+	return l.PerUser.IsEnabled() || l.PerNode.IsEnabled() || l.PerClientIP.IsEnabled() || l.Global.IsEnabled()
+}
+
+// IsEnabled method returns true if the rate or the concurrency are limited.
+func (l *RateLimit) IsEnabled() bool {
+	return l.Requests > 0 || l.MaxConcurrent > 0
+}
+
+func (l *RateLimit) GetPeriod() time.Duration {
+	if l.Period <= 0 {
+		return DefaultRateLimitPeriod
+	}
+	return l.Period
+}
+
+func (l *RateLimit) GetBurst() int {
+	if l.Burst <= 0 {
+		return l.Requests
+	}
+	return l.Burst
+}
+
+// GetRate method returns the allowed requests per second.
+func (l *RateLimit) GetRate() float64 {
+	return float64(l.Requests) / l.GetPeriod().Seconds()
+}
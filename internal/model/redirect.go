@@ -0,0 +1,82 @@
+// SPDX-FileCopyrightText: 2025 Hector @sudosu404 <hector@email.gnx>
+// SPDX-License-Identifier: AGPL3
+
+package model
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+type (
+	// Redirect struct stores the configuration of a redirect port.
+	Redirect struct {
+		// Status is 301, 302, 307 or 308, defaults to 301.
+		Status int `validate:"omitempty,oneof=301 302 307 308" yaml:"status,omitempty"`
+		// PreservePath appends the request path and query to the target URL.
+		PreservePath bool `yaml:"preservePath,omitempty"`
+		// HTTPS redirects to https on the same hostname, no target is needed.
+		HTTPS bool `yaml:"https,omitempty"`
+		// Rules are evaluated in order before the target, the first match is used.
+		Rules []RedirectRule `validate:"dive" yaml:"rules,omitempty"`
+	}
+
+	// RedirectRule struct stores a regular expression redirect.
+	RedirectRule struct {
+		// Match is a regular expression matched against the request path.
+		Match string `validate:"required" yaml:"match"`
+		// Target is the location, with $1 or ${name} references to the groups of Match.
+		// Targets starting with "/" are on the same host. The request query is
+		// appended if the target has no query.
+		Target string `validate:"required" yaml:"target"`
+		// Status defaults to the status of the port.
+		Status int `validate:"omitempty,oneof=301 302 307 308" yaml:"status,omitempty"`
+	}
+)
+
+const (
+	// RedirectHTTPS is the target of the "http->https on the same hostname" redirect ports.
+	RedirectHTTPS = "https"
+
+	DefaultRedirectStatus = http.StatusMovedPermanently
+)
+
+var ErrInvalidRedirectStatus = errors.New("invalid redirect status, expected 301, 302, 307 or 308")
+
+// ParseRedirectStatus function parses a redirect status code.
+func ParseRedirectStatus(s string) (int, error) {
+	status, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil || !IsRedirectStatus(status) {
+		return 0, fmt.Errorf("%w: %s", ErrInvalidRedirectStatus, s)
+	}
+
+	return status, nil
+}
+
+// IsRedirectStatus function returns true for the supported redirect status codes.
+func IsRedirectStatus(status int) bool {
+	switch status {
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+		return true
+	}
+	return false
+}
+
+// GetStatus method returns the status of the port, 301 by default.
+func (r *Redirect) GetStatus() int {
+	if !IsRedirectStatus(r.Status) {
+		return DefaultRedirectStatus
+	}
+	return r.Status
+}
+
+// GetStatus method returns the status of the rule or the status of the port.
+func (r *RedirectRule) GetStatus(portStatus int) int {
+	if !IsRedirectStatus(r.Status) {
+		return portStatus
+	}
+	return r.Status
+}
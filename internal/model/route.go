@@ -0,0 +1,49 @@
+// SPDX-FileCopyrightText: 2025 Hector @sudosu404 <hector@email.gnx>
+// SPDX-License-Identifier: AGPL3
+
+package model
+
+import (
+	"net/url"
+)
+
+type (
+	// Route struct stores a routing rule of a port.
+	// A request matches the route when all configured matchers match.
+	Route struct {
+		Name string `yaml:"name,omitempty"`
+		// PathPrefix matches the beginning of the request path.
+		PathPrefix string `yaml:"pathPrefix,omitempty"`
+		// PathRegex matches the request path with a regular expression.
+		PathRegex string `yaml:"pathRegex,omitempty"`
+		// Host matches the request Host header, "*.example.com" is allowed.
+		Host string `yaml:"host,omitempty"`
+		// Capability matches clients with an app capability granted in the
+		// tailnet policy file, "example.com/cap/*" is allowed.
+		Capability string `yaml:"capability,omitempty"`
+		// StripPrefix removes PathPrefix from the path sent to the target.
+		StripPrefix bool `yaml:"stripPrefix,omitempty"`
+		// Rewrite replaces PathPrefix or, with PathRegex, is the replacement
+		// template of the regular expression (ex: "/api/$1").
+		Rewrite string `yaml:"rewrite,omitempty"`
+
+		targets []*url.URL
+	}
+)
+
+func (r *Route) GetTargets() []*url.URL {
+	return r.targets
+}
+
+func (r *Route) AddTarget(target *url.URL) {
+	r.targets = append(r.targets, target)
+}
+
+// ReplaceTarget replaces a target URL with a new one.
+func (r *Route) ReplaceTarget(origin, target *url.URL) {
+	for k, v := range r.targets {
+		if v.String() == origin.String() {
+			r.targets[k] = target
+		}
+	}
+}
@@ -6,11 +6,15 @@ type (
 	ProxyStatus int
 
 	ProxyEvent struct {
-		ID      string
-		Port    string
-		AuthURL string
-		Status  ProxyStatus
+		ID           string
+		Port         string
+		AuthURL      string
+		Target       string
+		Status       ProxyStatus
+		TargetHealth TargetHealth
 	}
+
+	TargetHealth int
 )
 
 const (
@@ -36,3 +40,19 @@ var proxyStatusStrings = []string{
 func (s *ProxyStatus) String() string {
 	return proxyStatusStrings[int(*s)]
 }
+
+const (
+	TargetHealthUnknown TargetHealth = iota
+	TargetHealthHealthy
+	TargetHealthUnhealthy
+)
+
+var targetHealthStrings = []string{
+	"Unknown",
+	"Healthy",
+	"Unhealthy",
+}
+
+func (h *TargetHealth) String() string {
+	return targetHealthStrings[int(*h)]
+}
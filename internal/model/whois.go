@@ -3,7 +3,12 @@
 
 package model
 
-import "context"
+import (
+	"context"
+	"encoding/json"
+	"maps"
+	"slices"
+)
 
 type (
 	Whois struct {
@@ -11,6 +16,17 @@ type (
 		DisplayName   string
 		Username      string
 		ProfilePicURL string
+		// NodeName is the Tailscale machine name of the client.
+		NodeName string
+		// Tags are the ACL tags of the client node.
+		Tags []string
+		// NodeID is the stable ID of the client node.
+		NodeID string
+		// Addresses are the Tailscale IPs of the client node.
+		Addresses []string
+		// Capabilities are the app capabilities granted to the client
+		// by the tailnet policy file, with their values.
+		Capabilities map[string][]json.RawMessage
 	}
 )
 
@@ -30,6 +46,49 @@ func (w *Whois) GetProfilePicURL() string {
 	return w.ProfilePicURL
 }
 
+func (w *Whois) GetNodeName() string {
+	return w.NodeName
+}
+
+func (w *Whois) GetTags() []string {
+	return w.Tags
+}
+
+func (w *Whois) GetNodeID() string {
+	return w.NodeID
+}
+
+func (w *Whois) GetAddresses() []string {
+	return w.Addresses
+}
+
+func (w *Whois) GetCapabilities() map[string][]json.RawMessage {
+	return w.Capabilities
+}
+
+// IsTagged method returns true for tagged devices, they share the
+// "tagged-devices" user and are identified by their node.
+func (w *Whois) IsTagged() bool {
+	return len(w.Tags) > 0
+}
+
+// GetCapabilityNames method returns the sorted names of the granted capabilities.
+func (w *Whois) GetCapabilityNames() []string {
+	return slices.Sorted(maps.Keys(w.Capabilities))
+}
+
+// HasCapability method returns true if the client has a capability matching
+// the pattern, wildcards like "example.com/cap/*" are allowed.
+func (w *Whois) HasCapability(pattern string) bool {
+	for name := range w.Capabilities {
+		if matchPattern(pattern, name) {
+			return true
+		}
+	}
+
+	return false
+}
+
 func WhoisFromContext(ctx context.Context) (Whois, bool) {
 	who, ok := ctx.Value(ContextKeyWhois).(Whois)
 
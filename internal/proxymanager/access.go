@@ -0,0 +1,41 @@
+// SPDX-FileCopyrightText: 2025 Hector @sudosu404 <hector@email.gnx>
+// SPDX-License-Identifier: AGPL3
+
+package proxymanager
+
+import (
+	"net/http"
+
+	"github.com/sudosu404/tailnet-lib/internal/model"
+
+	"github.com/rs/zerolog"
+)
+
+const accessDeniedMessage = "Your Tailscale identity is not allowed to access this service."
+
+// accessMiddleware function rejects the requests whose identity is not allowed by the policy.
+// The Whois of the request must be resolved before.
+func accessMiddleware(policy model.AccessPolicy, log zerolog.Logger, next http.Handler) http.Handler {
+	if !policy.IsEnabled() {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		who, _ := model.WhoisFromContext(r.Context())
+
+		if !policy.IsAllowed(who) {
+			log.Warn().
+				Str("user", who.Username).
+				Str("node", who.NodeName).
+				Strs("tags", who.Tags).
+				Str("remoteAddr", r.RemoteAddr).
+				Str("path", r.URL.Path).
+				Msg("access denied")
+
+			renderErrorPage(w, r, http.StatusForbidden, accessDeniedMessage)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
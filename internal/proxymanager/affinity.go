@@ -0,0 +1,122 @@
+// SPDX-FileCopyrightText: 2025 Hector @sudosu404 <hector@email.gnx>
+// SPDX-License-Identifier: AGPL3
+
+package proxymanager
+
+import (
+	"hash/fnv"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/sudosu404/tailnet-lib/internal/model"
+)
+
+// nextWithAffinity method returns the upstream of a request with the session affinity
+// of the port. Clients bound to an unhealthy upstream fail over to another one:
+// the cookie is replaced, and the hashes move only the clients of that upstream.
+func (b *balancer) nextWithAffinity(w http.ResponseWriter, r *http.Request) *upstream {
+	switch b.affinity.Type {
+	case model.SessionAffinityCookie:
+		return b.cookieAffinity(w, r)
+	case model.SessionAffinityNode:
+		who, _ := model.WhoisFromContext(r.Context())
+		if who.NodeID != "" {
+			return b.hashAffinity("node:" + who.NodeID)
+		}
+		// clients without node, like funnel requests, use the source IP
+		return b.hashAffinity("ip:" + clientIP(r))
+	case model.SessionAffinityIP:
+		return b.hashAffinity("ip:" + clientIP(r))
+	}
+
+	return b.next(r)
+}
+
+// cookieAffinity method returns the upstream of the affinity cookie if it's available,
+// otherwise it selects one with the strategy and sets the cookie.
+func (b *balancer) cookieAffinity(w http.ResponseWriter, r *http.Request) *upstream {
+	name := b.affinity.Cookie
+
+	if cookie, err := r.Cookie(name); err == nil {
+		for _, u := range b.upstreams {
+			if u.id == cookie.Value && u.isAvailable() {
+				return u
+			}
+		}
+	}
+
+	u := b.next(r)
+	if u == nil {
+		return nil
+	}
+
+	cookie := &http.Cookie{
+		Name:     name,
+		Value:    u.id,
+		Path:     "/",
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteLaxMode,
+	}
+	if b.affinity.CookieTTL > 0 {
+		cookie.MaxAge = int(b.affinity.CookieTTL.Seconds())
+	}
+	http.SetCookie(w, cookie)
+
+	return u
+}
+
+// hashAffinity method selects the available upstream with the highest
+// rendezvous hash of the key, the weights are not used.
+func (b *balancer) hashAffinity(key string) *upstream {
+	var (
+		selected *upstream
+		best     uint64
+	)
+
+	for _, u := range b.upstreams {
+		if !u.isAvailable() {
+			continue
+		}
+
+		h := fnv.New64a()
+		_, _ = h.Write([]byte(key))
+		_, _ = h.Write([]byte(u.id))
+
+		if score := mix64(h.Sum64()); selected == nil || score > best {
+			selected, best = u, score
+		}
+	}
+
+	return selected
+}
+
+// mix64 function spreads the bits of a hash, fnv alone keeps similar keys close.
+func mix64(x uint64) uint64 {
+	x ^= x >> 33 //nolint:mnd
+	x *= 0xff51afd7ed558ccd
+	x ^= x >> 33 //nolint:mnd
+	x *= 0xc4ceb9fe1a85ec53
+	x ^= x >> 33 //nolint:mnd
+
+	return x
+}
+
+// upstreamID function returns a short stable identifier of a target.
+func upstreamID(target *url.URL) string {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(target.String()))
+
+	return strconv.FormatUint(h.Sum64(), 36) //nolint:mnd
+}
+
+// clientIP function returns the address of the client without the port.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,130 @@
+// SPDX-FileCopyrightText: 2025 Hector @sudosu404 <hector@email.gnx>
+// SPDX-License-Identifier: AGPL3
+
+package proxymanager
+
+import (
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/sudosu404/tailnet-lib/internal/model"
+
+	"github.com/rs/zerolog"
+)
+
+func TestHashAffinityMovesOnlyClientsOfUnhealthyUpstream(t *testing.T) {
+	b := newTestBalancer(t, model.LoadBalancer{}, 4)
+
+	before := map[string]*upstream{}
+	used := map[*upstream]bool{}
+	for i := range 200 {
+		key := "node:" + strconv.Itoa(i)
+		before[key] = b.hashAffinity(key)
+		used[before[key]] = true
+
+		if again := b.hashAffinity(key); again != before[key] {
+			t.Fatalf("key %s moved without health change", key)
+		}
+	}
+
+	if len(used) != len(b.upstreams) {
+		t.Errorf("keys spread over %d of %d upstreams", len(used), len(b.upstreams))
+	}
+
+	down := b.upstreams[2]
+	down.health = model.TargetHealthUnhealthy
+
+	for key, u := range before {
+		after := b.hashAffinity(key)
+		switch {
+		case after == down:
+			t.Fatalf("key %s bound to unhealthy upstream", key)
+		case u != down && after != u:
+			t.Errorf("key %s moved from a healthy upstream", key)
+		}
+	}
+}
+
+func TestCookieAffinity(t *testing.T) {
+	b := newTestBalancer(t, model.LoadBalancer{
+		Affinity: model.SessionAffinity{Type: model.SessionAffinityCookie},
+	}, 3)
+
+	w := httptest.NewRecorder()
+	first := b.nextWithAffinity(w, httptest.NewRequest("GET", "/", nil))
+
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 {
+		t.Fatalf("got %d cookies, want 1", len(cookies))
+	}
+	cookie := cookies[0]
+	if cookie.Name != "tailnet_affinity_443" || cookie.Value != first.id {
+		t.Errorf("unexpected cookie %s=%s", cookie.Name, cookie.Value)
+	}
+
+	for range 5 {
+		r := httptest.NewRequest("GET", "/", nil)
+		r.AddCookie(cookie)
+		if u := b.nextWithAffinity(httptest.NewRecorder(), r); u != first {
+			t.Fatal("request with cookie moved to another upstream")
+		}
+	}
+
+	first.health = model.TargetHealthUnhealthy
+
+	r := httptest.NewRequest("GET", "/", nil)
+	r.AddCookie(cookie)
+	w = httptest.NewRecorder()
+	if u := b.nextWithAffinity(w, r); u == first {
+		t.Fatal("request with cookie sent to unhealthy upstream")
+	}
+	if cookies := w.Result().Cookies(); len(cookies) != 1 || cookies[0].Value == first.id {
+		t.Error("cookie of unhealthy upstream not replaced")
+	}
+}
+
+func TestAffinityCookieName(t *testing.T) {
+	tests := []struct {
+		name     string
+		affinity model.SessionAffinity
+		port     int
+		want     string
+	}{
+		{name: "default", port: 443, want: "tailnet_affinity_443"},
+		{name: "default other port", port: 8443, want: "tailnet_affinity_8443"},
+		{name: "custom", affinity: model.SessionAffinity{Cookie: "sticky"}, port: 443, want: "sticky"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.affinity.GetCookie(tt.port); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRouteAffinityCookie(t *testing.T) {
+	pconfig := model.PortConfig{
+		ProxyPort: 8443,
+		LoadBalancer: model.LoadBalancer{
+			Affinity: model.SessionAffinity{Type: model.SessionAffinityCookie},
+		},
+		Routes: []model.Route{{Name: "api", PathPrefix: "/api"}},
+	}
+	pconfig.Routes[0].AddTarget(&url.URL{Scheme: "http", Host: "api:8080"})
+
+	rt := newRouter(pconfig, newBalancer(pconfig), zerolog.Nop())
+	if len(rt.routes) != 1 {
+		t.Fatalf("got %d routes, want 1", len(rt.routes))
+	}
+
+	if got := rt.routes[0].balancer.affinity.Cookie; got != "tailnet_affinity_8443_0" {
+		t.Errorf("got route cookie %q", got)
+	}
+	if got := rt.fallback.affinity.Cookie; got != "tailnet_affinity_8443" {
+		t.Errorf("got port cookie %q", got)
+	}
+}
@@ -0,0 +1,207 @@
+// SPDX-FileCopyrightText: 2025 Hector @sudosu404 <hector@email.gnx>
+// SPDX-License-Identifier: AGPL3
+
+package proxymanager
+
+import (
+	"context"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"sync"
+	"sync/atomic"
+
+	"github.com/sudosu404/tailnet-lib/internal/model"
+)
+
+type (
+	// upstream struct stores the runtime state of a port target.
+	upstream struct {
+		url    *url.URL
+		weight int
+		active atomic.Int64
+		// id identifies the upstream in the affinity cookie without exposing its URL
+		id string
+
+		// current weight used by the smooth weighted round robin
+		currentWeight int
+
+		// health check state
+		health    model.TargetHealth
+		successes int
+		failures  int
+		mtx       sync.RWMutex
+	}
+
+	// balancer struct selects an upstream for each request.
+	balancer struct {
+		strategy  model.LoadBalancerStrategy
+		affinity  model.SessionAffinity
+		upstreams []*upstream
+		counter   atomic.Uint64
+		mtx       sync.Mutex
+	}
+
+	contextKey string
+)
+
+const contextKeyUpstream contextKey = "contextkey.upstream"
+
+// newBalancer function creates a balancer for all targets of a port.
+func newBalancer(pconfig model.PortConfig) *balancer {
+	targets := pconfig.GetTargets()
+
+	upstreams := make([]*upstream, len(targets))
+	for i, target := range targets {
+		upstreams[i] = &upstream{
+			url:    target,
+			weight: pconfig.LoadBalancer.GetWeight(i),
+			id:     upstreamID(target),
+		}
+	}
+
+	affinity := pconfig.LoadBalancer.Affinity
+	affinity.Cookie = affinity.GetCookie(pconfig.ProxyPort)
+
+	return &balancer{
+		strategy:  pconfig.LoadBalancer.GetStrategy(),
+		affinity:  affinity,
+		upstreams: upstreams,
+	}
+}
+
+// getHealth method returns the current health of the upstream.
+func (u *upstream) getHealth() model.TargetHealth {
+	u.mtx.RLock()
+	defer u.mtx.RUnlock()
+
+	return u.health
+}
+
+// address method returns the host:port of the upstream, the port defaults to the one of its scheme.
+func (u *upstream) address() string {
+	if u.url.Port() == "" {
+		return net.JoinHostPort(u.url.Hostname(), defaultPortForScheme(u.url.Scheme))
+	}
+	return u.url.Host
+}
+
+// isAvailable method returns false when the upstream was taken out of rotation.
+func (u *upstream) isAvailable() bool {
+	return u.getHealth() != model.TargetHealthUnhealthy
+}
+
+// next method returns the upstream that should handle the request.
+func (b *balancer) next(_ *http.Request) *upstream {
+	return b.pick(b.available())
+}
+
+// nextSkipping method returns the next upstream that isn't in skip,
+// it's used to retry the connections whose upstream failed.
+func (b *balancer) nextSkipping(skip []*upstream) *upstream {
+	return b.pick(slices.DeleteFunc(b.available(), func(u *upstream) bool {
+		return slices.Contains(skip, u)
+	}))
+}
+
+// pick method selects one of the upstreams with the balancer strategy.
+func (b *balancer) pick(upstreams []*upstream) *upstream {
+	switch len(upstreams) {
+	case 0:
+		return nil
+	case 1:
+		return upstreams[0]
+	}
+
+	switch b.strategy {
+	case model.LoadBalancerRandom:
+		return upstreams[rand.IntN(len(upstreams))] //nolint:gosec
+	case model.LoadBalancerLeastConnections:
+		return b.leastConnections(upstreams)
+	case model.LoadBalancerWeighted:
+		return b.weighted(upstreams)
+	default:
+		return b.roundRobin(upstreams)
+	}
+}
+
+// available method returns the upstreams that are in rotation.
+func (b *balancer) available() []*upstream {
+	upstreams := make([]*upstream, 0, len(b.upstreams))
+	for _, u := range b.upstreams {
+		if u.isAvailable() {
+			upstreams = append(upstreams, u)
+		}
+	}
+
+	return upstreams
+}
+
+func (b *balancer) roundRobin(upstreams []*upstream) *upstream {
+	n := b.counter.Add(1) - 1
+	return upstreams[n%uint64(len(upstreams))]
+}
+
+func (b *balancer) leastConnections(upstreams []*upstream) *upstream {
+	var selected *upstream
+
+	// start on a rotating index so ties are spread across upstreams
+	start := int(b.counter.Add(1) % uint64(len(upstreams)))
+	for i := range upstreams {
+		u := upstreams[(start+i)%len(upstreams)]
+		if selected == nil || u.active.Load() < selected.active.Load() {
+			selected = u
+		}
+	}
+
+	return selected
+}
+
+// weighted method implements the smooth weighted round robin used by nginx.
+func (b *balancer) weighted(upstreams []*upstream) *upstream {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	var selected *upstream
+	total := 0
+
+	for _, u := range upstreams {
+		u.currentWeight += u.weight
+		total += u.weight
+
+		if selected == nil || u.currentWeight > selected.currentWeight {
+			selected = u
+		}
+	}
+
+	selected.currentWeight -= total
+
+	return selected
+}
+
+// serve method selects an upstream, stores it in the request context and calls next.
+func (b *balancer) serve(w http.ResponseWriter, r *http.Request, next http.Handler) {
+	u := b.nextWithAffinity(w, r)
+	if u == nil {
+		renderErrorPage(w, r, http.StatusServiceUnavailable, "")
+		return
+	}
+
+	u.active.Add(1)
+	defer u.active.Add(-1)
+
+	if info, ok := requestInfoFromContext(r.Context()); ok {
+		info.upstream = u.url.String()
+	}
+
+	ctx := context.WithValue(r.Context(), contextKeyUpstream, u)
+	next.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// upstreamFromContext function returns the upstream selected for the request.
+func upstreamFromContext(ctx context.Context) (*upstream, bool) {
+	u, ok := ctx.Value(contextKeyUpstream).(*upstream)
+	return u, ok
+}
@@ -0,0 +1,103 @@
+// SPDX-FileCopyrightText: 2025 Hector @sudosu404 <hector@email.gnx>
+// SPDX-License-Identifier: AGPL3
+
+package proxymanager
+
+import (
+	"net/url"
+	"slices"
+	"strconv"
+	"testing"
+
+	"github.com/sudosu404/tailnet-lib/internal/model"
+)
+
+func newTestBalancer(t *testing.T, lb model.LoadBalancer, n int) *balancer {
+	t.Helper()
+
+	pconfig := model.PortConfig{ProxyPort: 443, LoadBalancer: lb}
+	for i := range n {
+		target, err := url.Parse("http://target-" + strconv.Itoa(i) + ":8080")
+		if err != nil {
+			t.Fatal(err)
+		}
+		pconfig.AddTarget(target)
+	}
+
+	return newBalancer(pconfig)
+}
+
+func TestBalancerWeighted(t *testing.T) {
+	b := newTestBalancer(t, model.LoadBalancer{
+		Strategy: model.LoadBalancerWeighted,
+		Weights:  []int{5, 1, 1},
+	}, 3)
+
+	picks := make([]string, 0, 7)
+	counts := map[*upstream]int{}
+	for range 7 {
+		u := b.next(nil)
+		counts[u]++
+		picks = append(picks, upstreamIndex(u))
+	}
+
+	for i, want := range []int{5, 1, 1} {
+		if got := counts[b.upstreams[i]]; got != want {
+			t.Errorf("upstream %d: got %d requests, want %d", i, got, want)
+		}
+	}
+
+	// the smooth weighted round robin interleaves the heavy upstream
+	want := []string{"0", "0", "1", "0", "2", "0", "0"}
+	if !slices.Equal(picks, want) {
+		t.Errorf("got order %v, want %v", picks, want)
+	}
+}
+
+func TestBalancerWeightsDefaultToOne(t *testing.T) {
+	lb := model.LoadBalancer{Weights: []int{3, 0}}
+
+	tests := []struct {
+		index int
+		want  int
+	}{
+		{index: 0, want: 3},
+		{index: 1, want: 1},
+		{index: 2, want: 1},
+	}
+
+	for _, tt := range tests {
+		if got := lb.GetWeight(tt.index); got != tt.want {
+			t.Errorf("weight %d: got %d, want %d", tt.index, got, tt.want)
+		}
+	}
+}
+
+func TestBalancerSkipsUnhealthy(t *testing.T) {
+	for _, strategy := range []model.LoadBalancerStrategy{
+		model.LoadBalancerRoundRobin,
+		model.LoadBalancerRandom,
+		model.LoadBalancerLeastConnections,
+		model.LoadBalancerWeighted,
+	} {
+		t.Run(string(strategy), func(t *testing.T) {
+			b := newTestBalancer(t, model.LoadBalancer{Strategy: strategy}, 3)
+			b.upstreams[1].health = model.TargetHealthUnhealthy
+
+			for range 20 {
+				if u := b.next(nil); u == b.upstreams[1] {
+					t.Fatal("unhealthy upstream selected")
+				}
+			}
+
+			if u := b.nextSkipping([]*upstream{b.upstreams[0], b.upstreams[2]}); u != nil {
+				t.Errorf("got %s, want no upstream", u.url)
+			}
+		})
+	}
+}
+
+// upstreamIndex function returns the index of an upstream in its target hostname.
+func upstreamIndex(u *upstream) string {
+	return u.url.Hostname()[len("target-"):]
+}
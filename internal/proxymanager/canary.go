@@ -0,0 +1,162 @@
+// SPDX-FileCopyrightText: 2025 Hector @sudosu404 <hector@email.gnx>
+// SPDX-License-Identifier: AGPL3
+
+package proxymanager
+
+import (
+	"hash/fnv"
+	"math"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync/atomic"
+
+	"github.com/sudosu404/tailnet-lib/internal/model"
+
+	"github.com/rs/zerolog"
+)
+
+type (
+	// canary struct sends part of the port requests to a canary target.
+	// The weight can be changed at runtime, the users keep their assignment
+	// while the weight grows: a user with bucket b gets the canary when b < weight.
+	canary struct {
+		config   model.Canary
+		balancer *balancer
+		weight   atomic.Uint64
+	}
+
+	// CanaryState struct is the runtime state of the canary of a port.
+	CanaryState struct {
+		Port   string  `json:"port"`
+		Target string  `json:"target"`
+		Weight float64 `json:"weight"`
+	}
+)
+
+// canaryBuckets is the resolution of the weights, 0.01%.
+const canaryBuckets = 10000
+
+// newCanary function returns the canary of a port, nil if the port has no canary.
+func newCanary(pconfig model.PortConfig, log zerolog.Logger) *canary {
+	if !pconfig.Canary.IsEnabled() {
+		return nil
+	}
+
+	target, err := url.Parse(pconfig.Canary.Target)
+	if err != nil || target.Scheme == "" || target.Host == "" {
+		log.Error().Err(err).Str("canary", pconfig.Canary.Target).Msg("invalid canary target URL")
+		return nil
+	}
+
+	canaryConfig := model.PortConfig{}
+	canaryConfig.AddTarget(target)
+
+	c := &canary{
+		config:   pconfig.Canary,
+		balancer: newBalancer(canaryConfig),
+	}
+	c.setWeight(pconfig.Canary.Weight)
+
+	return c
+}
+
+// middleware method sends the requests assigned to the canary to its target,
+// the other requests, and all of them while the canary is unhealthy, use stable.
+func (c *canary) middleware(stable, next http.Handler) http.Handler {
+	if c == nil {
+		return stable
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if c.isCanary(r) && c.balancer.next(r) != nil {
+			c.balancer.serve(w, r, next)
+			return
+		}
+
+		stable.ServeHTTP(w, r)
+	})
+}
+
+// isCanary method returns true if the request is assigned to the canary.
+func (c *canary) isCanary(r *http.Request) bool {
+	if c.config.Header != "" {
+		if selected, ok := canaryValue(r.Header.Get(c.config.Header)); ok {
+			return selected
+		}
+	}
+
+	if c.config.Cookie != "" {
+		if cookie, err := r.Cookie(c.config.Cookie); err == nil {
+			if selected, ok := canaryValue(cookie.Value); ok {
+				return selected
+			}
+		}
+	}
+
+	who, _ := model.WhoisFromContext(r.Context())
+	if c.config.Match.Matches(who) {
+		return true
+	}
+
+	weight := c.getWeight()
+	switch {
+	case weight <= 0:
+		return false
+	case weight >= 100: //nolint:mnd
+		return true
+	}
+
+	return canaryBucket(stickyKey(r, who)) < uint64(weight*canaryBuckets/100) //nolint:mnd
+}
+
+func (c *canary) setWeight(weight float64) {
+	if math.IsNaN(weight) {
+		weight = 0
+	}
+	c.weight.Store(math.Float64bits(min(max(weight, 0), 100))) //nolint:mnd
+}
+
+func (c *canary) getWeight() float64 {
+	return math.Float64frombits(c.weight.Load())
+}
+
+// upstreams method returns the canary upstreams, checked by the port health checks.
+func (c *canary) upstreams() []*upstream {
+	if c == nil {
+		return nil
+	}
+	return c.balancer.upstreams
+}
+
+// canaryValue function returns the target selected by a header or cookie value.
+func canaryValue(value string) (bool, bool) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case model.CanaryValueCanary:
+		return true, true
+	case model.CanaryValueStable:
+		return false, true
+	}
+	return false, false
+}
+
+// stickyKey function returns the key that identifies the client of a request:
+// the Tailscale user, the node for tagged devices, or the client address.
+func stickyKey(r *http.Request, who model.Whois) string {
+	switch {
+	case who.IsTagged() && who.NodeID != "":
+		return "node:" + who.NodeID
+	case who.ID != "":
+		return "user:" + who.ID
+	}
+
+	return "addr:" + clientIP(r)
+}
+
+// canaryBucket function returns the bucket of a client, between 0 and canaryBuckets.
+func canaryBucket(key string) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(key))
+
+	return h.Sum64() % canaryBuckets
+}
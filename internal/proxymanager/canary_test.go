@@ -0,0 +1,159 @@
+// SPDX-FileCopyrightText: 2025 Hector @sudosu404 <hector@email.gnx>
+// SPDX-License-Identifier: AGPL3
+
+package proxymanager
+
+import (
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/sudosu404/tailnet-lib/internal/model"
+
+	"github.com/rs/zerolog"
+)
+
+func TestStickyKey(t *testing.T) {
+	tests := []struct {
+		name string
+		who  model.Whois
+		want string
+	}{
+		{
+			name: "user",
+			who:  model.Whois{ID: "u1", NodeID: "n1"},
+			want: "user:u1",
+		},
+		{
+			name: "tagged device",
+			who:  model.Whois{ID: "tagged", NodeID: "n1", Tags: []string{"tag:server"}},
+			want: "node:n1",
+		},
+		{
+			name: "anonymous",
+			who:  model.Whois{},
+			want: "addr:100.64.0.1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.RemoteAddr = "100.64.0.1:1234"
+
+			if got := stickyKey(r, tt.who); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCanaryAssignment(t *testing.T) {
+	pconfig := model.PortConfig{Canary: model.Canary{
+		Target: "http://canary:8080",
+		Header: "X-Canary",
+		Cookie: "canary",
+		Match:  model.AccessRule{Users: []string{"alice@example.com"}},
+	}}
+
+	c := newCanary(pconfig, zerolog.Nop())
+	if c == nil {
+		t.Fatal("canary not created")
+	}
+
+	tests := []struct {
+		name   string
+		weight float64
+		header string
+		cookie string
+		user   string
+		want   bool
+	}{
+		{name: "no weight", want: false},
+		{name: "full weight", weight: 100, want: true},
+		{name: "header canary", header: "canary", want: true},
+		{name: "header stable", weight: 100, header: "Stable", want: false},
+		{name: "header before cookie", header: "stable", cookie: "canary", want: false},
+		{name: "cookie canary", cookie: "canary", want: true},
+		{name: "invalid header", weight: 100, header: "other", want: true},
+		{name: "match", user: "alice@example.com", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c.setWeight(tt.weight)
+
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.header != "" {
+				r.Header.Set("X-Canary", tt.header)
+			}
+			if tt.cookie != "" {
+				r.AddCookie(&http.Cookie{Name: "canary", Value: tt.cookie})
+			}
+			if tt.user != "" {
+				r = r.WithContext(model.WhoisNewContext(r.Context(), model.Whois{Username: tt.user}))
+			}
+
+			if got := c.isCanary(r); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCanaryWeightIsSticky(t *testing.T) {
+	c := newCanary(model.PortConfig{Canary: model.Canary{Target: "http://canary:8080"}}, zerolog.Nop())
+
+	requests := make([]*http.Request, 1000)
+	for i := range requests {
+		requests[i] = httptest.NewRequest(http.MethodGet, "/", nil)
+		requests[i].RemoteAddr = "100.64.0." + strconv.Itoa(i%250) + ":" + strconv.Itoa(i)
+		who := model.Whois{ID: "u" + strconv.Itoa(i)}
+		requests[i] = requests[i].WithContext(model.WhoisNewContext(requests[i].Context(), who))
+	}
+
+	c.setWeight(10)
+	selected := make([]bool, len(requests))
+	count := 0
+	for i, r := range requests {
+		selected[i] = c.isCanary(r)
+		if selected[i] {
+			count++
+		}
+	}
+
+	if count < 50 || count > 150 {
+		t.Errorf("got %d users on the canary with weight 10, want about 100", count)
+	}
+
+	// the users on the canary stay on it when the weight grows
+	c.setWeight(50)
+	for i, r := range requests {
+		if selected[i] && !c.isCanary(r) {
+			t.Fatalf("user %d left the canary", i)
+		}
+	}
+}
+
+func TestCanarySetWeight(t *testing.T) {
+	c := &canary{}
+
+	tests := []struct {
+		weight float64
+		want   float64
+	}{
+		{weight: 10, want: 10},
+		{weight: -1, want: 0},
+		{weight: 150, want: 100},
+		{weight: math.NaN(), want: 0},
+	}
+
+	for _, tt := range tests {
+		c.setWeight(tt.weight)
+		if got := c.getWeight(); got != tt.want {
+			t.Errorf("setWeight(%v): got %v, want %v", tt.weight, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,331 @@
+// SPDX-FileCopyrightText: 2025 Hector @sudosu404 <hector@email.gnx>
+// SPDX-License-Identifier: AGPL3
+
+package proxymanager
+
+import (
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/sudosu404/tailnet-lib/internal/model"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/gzip"
+	"github.com/klauspost/compress/zstd"
+)
+
+type (
+	// compressor interface is implemented by the gzip, brotli and zstd writers.
+	compressor interface {
+		io.WriteCloser
+		Flush() error
+		Reset(w io.Writer)
+	}
+
+	// compressWriter struct compresses the response once it's known to be large enough.
+	// Smaller responses are buffered and sent unchanged.
+	compressWriter struct {
+		http.ResponseWriter
+		compression *compression
+		encoding    string
+		enc         compressor
+		buf         []byte
+		status      int
+		wroteHeader bool
+		decided     bool
+	}
+
+	// compression struct stores the compression configuration of a port.
+	compression struct {
+		algorithms []string
+		minSize    int
+		skipTypes  []string
+	}
+)
+
+const (
+	brotliLevel = 4
+)
+
+// compressedTypes are not compressed again, a type ending with "/" matches all its subtypes.
+var compressedTypes = []string{
+	"image/", "video/", "audio/",
+	"font/woff", "font/woff2",
+	"application/zip", "application/gzip", "application/x-gzip", "application/zstd",
+	"application/x-bzip2", "application/x-xz", "application/x-7z-compressed",
+	"application/x-rar-compressed", "application/vnd.rar",
+	"application/octet-stream", "application/pdf",
+	"application/grpc", "application/grpc-web", "application/grpc-web-text",
+	"text/event-stream",
+}
+
+// compressibleImages are image types that are text.
+var compressibleImages = []string{"image/svg+xml", "image/x-icon", "image/bmp"}
+
+var compressorPools = map[string]*sync.Pool{
+	model.CompressionGzip: {New: func() any {
+		w, _ := gzip.NewWriterLevel(nil, gzip.DefaultCompression)
+		return w
+	}},
+	model.CompressionBrotli: {New: func() any {
+		return brotli.NewWriterLevel(nil, brotliLevel)
+	}},
+	model.CompressionZstd: {New: func() any {
+		w, _ := zstd.NewWriter(nil, zstd.WithEncoderConcurrency(1), zstd.WithEncoderLevel(zstd.SpeedDefault))
+		return w
+	}},
+}
+
+// compressionMiddleware function compresses the responses with the best algorithm
+// accepted by the client.
+func compressionMiddleware(config model.Compression, next http.Handler) http.Handler {
+	if !config.Enabled {
+		return next
+	}
+
+	c := &compression{
+		algorithms: config.GetAlgorithms(),
+		minSize:    int(config.GetMinSize()),
+		skipTypes:  config.SkipTypes,
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		encoding := c.negotiate(r)
+		if encoding == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		cw := &compressWriter{
+			ResponseWriter: w,
+			compression:    c,
+			encoding:       encoding,
+		}
+		defer cw.close()
+
+		next.ServeHTTP(cw, r)
+	})
+}
+
+// negotiate method returns the algorithm for the request, empty if the response
+// must not be compressed.
+func (c *compression) negotiate(r *http.Request) string {
+	if r.Method == http.MethodHead || r.Header.Get("Range") != "" || r.Header.Get("Upgrade") != "" {
+		return ""
+	}
+
+	accepted := parseAcceptEncoding(r.Header.Get("Accept-Encoding"))
+	for _, algorithm := range c.algorithms {
+		q, ok := accepted[algorithm]
+		if !ok {
+			q, ok = accepted["*"]
+		}
+		if ok && q > 0 {
+			return algorithm
+		}
+	}
+
+	return ""
+}
+
+// parseAcceptEncoding function returns the quality of each accepted encoding.
+func parseAcceptEncoding(header string) map[string]float64 {
+	accepted := make(map[string]float64)
+
+	for _, part := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(part, ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				q = f
+			}
+		}
+
+		accepted[name] = q
+	}
+
+	return accepted
+}
+
+// compressible method returns true if the content type may be compressed.
+func (c *compression) compressible(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+
+	for _, t := range compressibleImages {
+		if mediaType == t {
+			return true
+		}
+	}
+
+	return !matchContentType(mediaType, compressedTypes) && !matchContentType(mediaType, c.skipTypes)
+}
+
+func matchContentType(mediaType string, types []string) bool {
+	for _, t := range types {
+		t = strings.ToLower(strings.TrimSpace(t))
+		if mediaType == t || (strings.HasSuffix(t, "/") && strings.HasPrefix(mediaType, t)) {
+			return true
+		}
+	}
+	return false
+}
+
+func (w *compressWriter) WriteHeader(status int) {
+	if w.wroteHeader {
+		return
+	}
+
+	// informational responses are sent as they are
+	if status < http.StatusOK {
+		w.ResponseWriter.WriteHeader(status)
+		return
+	}
+
+	w.wroteHeader = true
+	w.status = status
+
+	h := w.Header()
+
+	if status == http.StatusNoContent || status == http.StatusNotModified ||
+		h.Get("Content-Encoding") != "" || !w.compression.compressible(h.Get("Content-Type")) {
+		w.passthrough()
+		return
+	}
+
+	h.Add("Vary", "Accept-Encoding")
+
+	if cl := h.Get("Content-Length"); cl != "" {
+		if n, err := strconv.Atoi(cl); err == nil {
+			if n < w.compression.minSize {
+				w.passthrough()
+			} else {
+				w.startCompression()
+			}
+		}
+	}
+}
+
+func (w *compressWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	if w.decided {
+		if w.enc != nil {
+			return w.enc.Write(b)
+		}
+		return w.ResponseWriter.Write(b)
+	}
+
+	w.buf = append(w.buf, b...)
+	if len(w.buf) >= w.compression.minSize {
+		w.startCompression()
+		if err := w.flushBuffer(); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(b), nil
+}
+
+// Flush method sends the buffered response. Flushes before the response reaches the
+// minimum size are delayed until the compression is decided, the reverse proxy flushes
+// chunked responses after each write. Streaming types, like text/event-stream and
+// gRPC, are never compressed and flushed at once.
+func (w *compressWriter) Flush() {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	if !w.decided {
+		return
+	}
+
+	if w.enc != nil {
+		_ = w.enc.Flush()
+	}
+
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap method returns the original writer, used by http.ResponseController.
+func (w *compressWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// passthrough method sends the response without compression.
+func (w *compressWriter) passthrough() {
+	w.decided = true
+	w.ResponseWriter.WriteHeader(w.status)
+}
+
+func (w *compressWriter) startCompression() {
+	w.decided = true
+
+	h := w.Header()
+	h.Set("Content-Encoding", w.encoding)
+	h.Del("Content-Length")
+	h.Del("Accept-Ranges")
+
+	// the compressed representation is not byte for byte the same
+	if etag := h.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+		h.Set("ETag", "W/"+etag)
+	}
+
+	w.enc, _ = compressorPools[w.encoding].Get().(compressor)
+	w.enc.Reset(w.ResponseWriter)
+
+	w.ResponseWriter.WriteHeader(w.status)
+}
+
+func (w *compressWriter) flushBuffer() error {
+	if len(w.buf) == 0 {
+		return nil
+	}
+
+	buf := w.buf
+	w.buf = nil
+
+	var err error
+	if w.enc != nil {
+		_, err = w.enc.Write(buf)
+	} else {
+		_, err = w.ResponseWriter.Write(buf)
+	}
+
+	return err
+}
+
+// close method sends the small responses and finishes the compressed stream.
+func (w *compressWriter) close() {
+	if !w.wroteHeader {
+		return
+	}
+
+	if !w.decided {
+		w.passthrough()
+	}
+
+	_ = w.flushBuffer()
+
+	if w.enc != nil {
+		_ = w.enc.Close()
+		w.enc.Reset(nil)
+		compressorPools[w.encoding].Put(w.enc)
+		w.enc = nil
+	}
+}
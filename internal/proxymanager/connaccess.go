@@ -0,0 +1,142 @@
+// SPDX-FileCopyrightText: 2025 Hector @sudosu404 <hector@email.gnx>
+// SPDX-License-Identifier: AGPL3
+
+package proxymanager
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/sudosu404/tailnet-lib/internal/accesslog"
+	"github.com/sudosu404/tailnet-lib/internal/model"
+
+	"github.com/rs/zerolog"
+)
+
+type (
+	// connAccess struct applies the access policies and the access log of the
+	// proxy to the connections of tcp, udp and tls ports.
+	connAccess struct {
+		log       zerolog.Logger
+		accessLog *accesslog.Logger
+		whoisAddr func(ctx context.Context, remoteAddr string) model.Whois
+		policies  []model.AccessPolicy
+		// resolveWhois is set when the policies, the access log or the
+		// PROXY protocol v2 header use the client identity.
+		resolveWhois bool
+		proxyName    string
+		portName     string
+		proto        string
+		timeout      time.Duration
+	}
+)
+
+// connMethod is the method of the access log entries of passthrough connections.
+const connMethod = "CONNECT"
+
+// newConnAccess function returns the access control of a passthrough port, the
+// access log is opened when the proxy has it enabled.
+func newConnAccess(pconfig model.PortConfig, proxyConfig *model.Config, log zerolog.Logger,
+	whoisAddr func(ctx context.Context, remoteAddr string) model.Whois,
+) *connAccess {
+	a := &connAccess{
+		log:       log,
+		whoisAddr: whoisAddr,
+		proxyName: proxyConfig.Hostname,
+		portName:  pconfig.String(),
+		proto:     strings.ToUpper(pconfig.ProxyProtocol),
+		timeout:   passthroughDialTimeout(pconfig),
+	}
+
+	for _, policy := range []model.AccessPolicy{proxyConfig.AccessPolicy, pconfig.AccessPolicy} {
+		if policy.IsEnabled() {
+			a.policies = append(a.policies, policy)
+		}
+	}
+
+	if proxyConfig.ProxyAccessLog {
+		accessLog, err := accesslog.Open(proxyConfig.AccessLog)
+		if err != nil {
+			log.Error().Err(err).Msg("error opening access log")
+		} else {
+			a.accessLog = accessLog
+		}
+	}
+
+	a.resolveWhois = whoisAddr != nil &&
+		(len(a.policies) > 0 || a.accessLog != nil || pconfig.SendProxyProtocol == model.ProxyProtocolV2)
+
+	return a
+}
+
+// whois method resolves the identity of a client when it's used.
+func (a *connAccess) whois(remoteAddr string) model.Whois {
+	if !a.resolveWhois {
+		return model.Whois{}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), a.timeout)
+	defer cancel()
+
+	return a.whoisAddr(ctx, remoteAddr)
+}
+
+// isAllowed method returns false if an access policy denies the client.
+func (a *connAccess) isAllowed(who model.Whois, remoteAddr string) bool {
+	for _, policy := range a.policies {
+		if !policy.IsAllowed(who) {
+			a.log.Warn().
+				Str("user", who.Username).
+				Str("node", who.NodeName).
+				Strs("tags", who.Tags).
+				Str("remoteAddr", remoteAddr).
+				Msg("access denied")
+
+			return false
+		}
+	}
+
+	return true
+}
+
+// logConn method writes the access log entry of a connection, the status is
+// 200 for forwarded connections, 403 when denied and 502 when no target answered.
+func (a *connAccess) logConn(start time.Time, client string, who model.Whois, serverName, upstream string,
+	status int, bytesIn, bytesOut int64,
+) {
+	if a.accessLog == nil {
+		return
+	}
+
+	e := &accesslog.Entry{
+		Time:     start,
+		Proxy:    a.proxyName,
+		Port:     a.portName,
+		Client:   client,
+		User:     who.Username,
+		Node:     who.NodeName,
+		Method:   connMethod,
+		Host:     serverName,
+		URI:      orPortName(serverName, a.portName),
+		Proto:    a.proto,
+		Status:   status,
+		BytesIn:  bytesIn,
+		BytesOut: bytesOut,
+		Upstream: upstream,
+		Duration: time.Since(start),
+	}
+
+	if err := a.accessLog.Log(e); err != nil {
+		a.log.Error().Err(err).Msg("error writing access log")
+	}
+}
+
+// orPortName function returns the server name of tls connections, or the port
+// name for the connections without it.
+func orPortName(serverName, portName string) string {
+	if serverName != "" {
+		return serverName
+	}
+	return portName
+}
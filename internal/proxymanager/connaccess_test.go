@@ -0,0 +1,125 @@
+// SPDX-FileCopyrightText: 2025 Hector @sudosu404 <hector@email.gnx>
+// SPDX-License-Identifier: AGPL3
+
+package proxymanager
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sudosu404/tailnet-lib/internal/accesslog"
+	"github.com/sudosu404/tailnet-lib/internal/model"
+
+	"github.com/rs/zerolog"
+)
+
+func TestTCPProxyAccess(t *testing.T) {
+	echo, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer echo.Close()
+
+	go func() {
+		for {
+			conn, err := echo.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				_, _ = io.Copy(conn, conn)
+			}()
+		}
+	}()
+
+	pconfig, err := model.NewPortShortLabel("5432/tcp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	target, _ := url.Parse("tcp://" + echo.Addr().String())
+	pconfig.AddTarget(target)
+	pconfig.AccessPolicy = model.AccessPolicy{Deny: model.AccessRule{Users: []string{"mallory@example.com"}}}
+
+	logFile := filepath.Join(t.TempDir(), "access.log")
+	proxyConfig := &model.Config{
+		Hostname:       "db",
+		ProxyAccessLog: true,
+		AccessLog:      model.AccessLog{Format: model.AccessLogCommon, Output: logFile},
+	}
+	defer accesslog.Close()
+
+	// the client identity is chosen by the test for each connection
+	user := make(chan string, 1)
+	whoisAddr := func(context.Context, string) model.Whois {
+		return model.Whois{Username: <-user}
+	}
+
+	access := newConnAccess(pconfig, proxyConfig, zerolog.Nop(), whoisAddr)
+	proxy := newTCPProxy(pconfig, newBalancer(pconfig), nil, nil, zerolog.Nop(), access)
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() { _ = proxy.Serve(l) }()
+	defer func() {
+		l.Close()
+		_ = proxy.Shutdown(context.Background())
+	}()
+
+	tests := []struct {
+		user    string
+		allowed bool
+	}{
+		{user: "alice@example.com", allowed: true},
+		{user: "mallory@example.com", allowed: false},
+	}
+
+	for _, tt := range tests {
+		user <- tt.user
+
+		conn, err := net.Dial("tcp", l.Addr().String())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_ = conn.SetDeadline(time.Now().Add(5 * time.Second))
+		_, _ = conn.Write([]byte("ping\n"))
+		line, _ := bufio.NewReader(conn).ReadString('\n')
+		conn.Close()
+
+		if got := line == "ping\n"; got != tt.allowed {
+			t.Errorf("%s: got allowed %v, want %v", tt.user, got, tt.allowed)
+		}
+	}
+
+	// the entries are written when the connections end
+	var content []byte
+	for range 50 {
+		content, _ = os.ReadFile(logFile)
+		if strings.Count(string(content), "\n") >= len(tests) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	for _, want := range []string{
+		`alice@example.com [`,
+		`"CONNECT 5432/tcp TCP" 200 5 "tcp://` + echo.Addr().String() + `"`,
+		`mallory@example.com [`,
+		`"CONNECT 5432/tcp TCP" 403 - "-"`,
+	} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("access log without %q:\n%s", want, content)
+		}
+	}
+}
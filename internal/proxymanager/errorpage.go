@@ -0,0 +1,95 @@
+// SPDX-FileCopyrightText: 2025 Hector @sudosu404 <hector@email.gnx>
+// SPDX-License-Identifier: AGPL3
+
+package proxymanager
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+
+	"github.com/sudosu404/tailnet-lib/internal/model"
+	"github.com/sudosu404/tailnet-lib/internal/ui"
+	"github.com/sudosu404/tailnet-lib/internal/ui/pages"
+)
+
+const (
+	contextKeyErrorPages contextKey = "contextkey.errorpages"
+
+	maintenanceTitle = "Under maintenance"
+)
+
+// defaultErrorMessages are shown when the error has no specific message.
+var defaultErrorMessages = map[int]string{
+	http.StatusForbidden:             "You don't have access to this resource.",
+	http.StatusNotFound:              "The requested resource was not found.",
+	http.StatusMethodNotAllowed:      "The request method is not allowed for this resource.",
+	http.StatusRequestTimeout:        "The request was not received in time.",
+	http.StatusRequestEntityTooLarge: "The request is larger than the size allowed by this service.",
+	http.StatusBadGateway:            "The service is not responding, please try again later.",
+	http.StatusServiceUnavailable:    "The service is temporarily unavailable, please try again later.",
+	http.StatusGatewayTimeout:        "The service took too long to respond.",
+}
+
+// errorPagesMiddleware function stores the error pages configuration of the proxy in the request context.
+func errorPagesMiddleware(config model.ErrorPages, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), contextKeyErrorPages, config)))
+	})
+}
+
+// renderErrorPage function writes the error page of a status code to the client.
+// The message configured in the proxy error pages replaces the given message.
+func renderErrorPage(w http.ResponseWriter, r *http.Request, statusCode int, message string) {
+	config, _ := r.Context().Value(contextKeyErrorPages).(model.ErrorPages)
+
+	if message == "" {
+		message = defaultErrorMessages[statusCode]
+	}
+
+	writeErrorPage(w, r, statusCode, pages.ErrorData{
+		StatusCode: statusCode,
+		Title:      http.StatusText(statusCode),
+		Message:    config.GetMessage(statusCode, message),
+		Brand:      config.GetBrand(),
+	})
+}
+
+// renderMaintenancePage function writes the maintenance page with the Retry-After header.
+func renderMaintenancePage(w http.ResponseWriter, r *http.Request, maintenance model.Maintenance) {
+	config, _ := r.Context().Value(contextKeyErrorPages).(model.ErrorPages)
+
+	w.Header().Set("Retry-After", strconv.Itoa(int(maintenance.GetRetryAfter().Seconds())))
+
+	writeErrorPage(w, r, http.StatusServiceUnavailable, pages.ErrorData{
+		StatusCode: http.StatusServiceUnavailable,
+		Title:      maintenanceTitle,
+		Message:    maintenance.GetMessage(),
+		Brand:      config.GetBrand(),
+	})
+}
+
+func writeErrorPage(w http.ResponseWriter, r *http.Request, statusCode int, data pages.ErrorData) {
+	// the request context may be done after a timeout, the page is still rendered
+	r = r.WithContext(context.WithoutCancel(r.Context()))
+
+	w.Header().Set("Cache-Control", "no-store")
+	_ = ui.RenderTemplWithStatus(w, r, pages.ErrorPage(data), statusCode)
+}
+
+// upstreamErrorStatus function returns the status code of an error of the reverse proxy,
+// 408 or 413 if the request body failed, 504 for timeouts and 502 for other errors.
+func upstreamErrorStatus(r *http.Request, err error) int {
+	if statusCode, ok := requestBodyStatus(r); ok {
+		return statusCode
+	}
+
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return http.StatusGatewayTimeout
+	}
+
+	return http.StatusBadGateway
+}
@@ -0,0 +1,210 @@
+// SPDX-FileCopyrightText: 2025 Hector @sudosu404 <hector@email.gnx>
+// SPDX-License-Identifier: AGPL3
+
+package proxymanager
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/sudosu404/tailnet-lib/internal/accesslog"
+	"github.com/sudosu404/tailnet-lib/internal/model"
+	"github.com/sudosu404/tailnet-lib/internal/ui"
+	"github.com/sudosu404/tailnet-lib/internal/ui/pages"
+
+	"github.com/rs/zerolog"
+)
+
+type (
+	// fileServer struct serves the local directory of a file:// target.
+	// The files are opened inside the directory, paths and symlinks can't escape it.
+	fileServer struct {
+		log             zerolog.Logger
+		dir             string
+		target          string
+		proxyName       string
+		portName        string
+		config          model.FileServer
+		responseHeaders *headerRewriter
+	}
+)
+
+// newFileServer function returns the handler of a port with a file:// target.
+func newFileServer(pconfig model.PortConfig, proxyName string, responseHeaders *headerRewriter,
+	log zerolog.Logger,
+) *fileServer {
+	target := pconfig.GetFirstTarget()
+
+	if info, err := os.Stat(target.Path); err != nil || !info.IsDir() {
+		log.Error().Err(err).Str("dir", target.Path).Msg("file target is not a directory")
+	}
+
+	return &fileServer{
+		log:             log,
+		dir:             target.Path,
+		target:          target.String(),
+		proxyName:       proxyName,
+		portName:        pconfig.String(),
+		config:          pconfig.Files,
+		responseHeaders: responseHeaders,
+	}
+}
+
+func (fsrv *fileServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	accesslog.SetUpstream(r.Context(), fsrv.target)
+
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		renderErrorPage(w, r, http.StatusMethodNotAllowed, "")
+		return
+	}
+
+	fsrv.responseHeaders.apply(w.Header(), newHeaderData(r, fsrv.proxyName, fsrv.portName))
+
+	name := path.Clean("/" + r.URL.Path)
+
+	f, info, err := fsrv.open(name)
+	if err != nil {
+		fsrv.serveError(w, r, err)
+		return
+	}
+	defer f.Close()
+
+	if !info.IsDir() {
+		serveFile(w, r, f, info)
+		return
+	}
+
+	// relative links of the directories need the trailing slash
+	if !strings.HasSuffix(r.URL.Path, "/") {
+		location := path.Base(name) + "/"
+		if r.URL.RawQuery != "" {
+			location += "?" + r.URL.RawQuery
+		}
+		http.Redirect(w, r, location, http.StatusMovedPermanently)
+		return
+	}
+
+	for _, index := range fsrv.config.GetIndexFiles() {
+		indexFile, indexInfo, err := fsrv.open(path.Join(name, index))
+		if err != nil {
+			continue
+		}
+		defer indexFile.Close()
+
+		if !indexInfo.IsDir() {
+			serveFile(w, r, indexFile, indexInfo)
+			return
+		}
+	}
+
+	if !fsrv.config.Listing {
+		renderErrorPage(w, r, http.StatusNotFound, "")
+		return
+	}
+
+	fsrv.serveListing(w, r, f, name)
+}
+
+// open method opens a file of the directory, name is a clean absolute path.
+func (fsrv *fileServer) open(name string) (*os.File, fs.FileInfo, error) {
+	rel := strings.TrimPrefix(name, "/")
+	if rel == "" {
+		rel = "."
+	}
+
+	f, err := os.OpenInRoot(fsrv.dir, rel)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, nil, err
+	}
+
+	return f, info, nil
+}
+
+func (fsrv *fileServer) serveError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, fs.ErrPermission):
+		renderErrorPage(w, r, http.StatusForbidden, "")
+	case errors.Is(err, fs.ErrNotExist):
+		renderErrorPage(w, r, http.StatusNotFound, "")
+	default:
+		// paths escaping the directory are not found
+		fsrv.log.Debug().Err(err).Str("path", r.URL.Path).Msg("error opening file")
+		renderErrorPage(w, r, http.StatusNotFound, "")
+	}
+}
+
+// serveListing method writes the content of a directory, sorted by name.
+func (fsrv *fileServer) serveListing(w http.ResponseWriter, r *http.Request, dir *os.File, name string) {
+	entries, err := dir.ReadDir(-1)
+	if err != nil {
+		fsrv.log.Error().Err(err).Str("path", name).Msg("error reading directory")
+		renderErrorPage(w, r, http.StatusInternalServerError, "")
+		return
+	}
+
+	slices.SortFunc(entries, func(a, b os.DirEntry) int { return strings.Compare(a.Name(), b.Name()) })
+
+	if name != "/" {
+		name += "/"
+	}
+
+	data := pages.DirListingData{
+		Path:    name,
+		Parent:  name != "/",
+		Entries: make([]pages.DirEntry, 0, len(entries)),
+	}
+
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+
+		entryName := entry.Name()
+		if entry.IsDir() {
+			entryName += "/"
+		}
+
+		data.Entries = append(data.Entries, pages.DirEntry{
+			Name: entryName,
+			// names with ":" would be parsed as a scheme
+			URL:     (&url.URL{Path: entryName}).String(),
+			IsDir:   entry.IsDir(),
+			Size:    info.Size(),
+			ModTime: info.ModTime(),
+		})
+	}
+
+	w.Header().Set("Cache-Control", "no-cache")
+
+	if r.Method == http.MethodHead {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		return
+	}
+
+	_ = ui.RenderTempl(w, r, pages.DirListing(data))
+}
+
+// serveFile function writes a file, http.ServeContent answers the range
+// and conditional requests with the ETag and the modification time.
+func serveFile(w http.ResponseWriter, r *http.Request, f *os.File, info fs.FileInfo) {
+	if _, ok := w.Header()["Etag"]; !ok {
+		w.Header().Set("ETag", fmt.Sprintf(`"%x-%x"`, info.ModTime().UnixNano(), info.Size()))
+	}
+
+	http.ServeContent(w, r, info.Name(), info.ModTime(), f)
+}
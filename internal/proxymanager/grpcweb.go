@@ -0,0 +1,258 @@
+// SPDX-FileCopyrightText: 2025 Hector @sudosu404 <hector@email.gnx>
+// SPDX-License-Identifier: AGPL3
+
+package proxymanager
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/sudosu404/tailnet-lib/internal/model"
+)
+
+type (
+	// grpcWebResponseWriter struct converts a gRPC response to gRPC-Web,
+	// the trailers are sent in the body as the last frame.
+	grpcWebResponseWriter struct {
+		http.ResponseWriter
+		header      http.Header
+		contentType string
+		trailers    []string
+		text        bool
+		wroteHeader bool
+	}
+
+	// grpcWebTextReader struct decodes a base64 request body,
+	// each message may be padded on its own.
+	grpcWebTextReader struct {
+		body io.ReadCloser
+		buf  []byte
+		out  []byte
+		err  error
+	}
+)
+
+const (
+	grpcContentType        = "application/grpc"
+	grpcWebContentType     = "application/grpc-web"
+	grpcWebTextContentType = "application/grpc-web-text"
+
+	// grpcWebTrailerFlag marks the frame with the trailers.
+	grpcWebTrailerFlag = 0x80
+	grpcFrameHeaderLen = 5
+	base64Quantum      = 4
+)
+
+// grpcWebMiddleware function bridges gRPC-Web requests of browser clients
+// to the HTTP/2 targets. Other requests are forwarded unchanged.
+func grpcWebMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentType := r.Header.Get("Content-Type")
+
+		u, ok := upstreamFromContext(r.Context())
+		if !ok || !model.IsHTTP2Scheme(u.url.Scheme) || !strings.HasPrefix(contentType, grpcWebContentType) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		text := strings.HasPrefix(contentType, grpcWebTextContentType)
+
+		out := r.Clone(r.Context())
+		out.Header.Set("Content-Type", grpcContentType+grpcContentTypeSuffix(contentType))
+		out.Header.Set("Te", "trailers")
+		out.Header.Del("Content-Length")
+
+		if text {
+			out.Body = &grpcWebTextReader{body: r.Body}
+			out.ContentLength = -1
+		}
+
+		rw := &grpcWebResponseWriter{
+			ResponseWriter: w,
+			header:         make(http.Header),
+			contentType:    contentType,
+			text:           text,
+		}
+
+		next.ServeHTTP(rw, out)
+		rw.finish()
+	})
+}
+
+// grpcContentTypeSuffix function returns the codec suffix of a gRPC-Web content type, like "+proto".
+func grpcContentTypeSuffix(contentType string) string {
+	contentType = strings.TrimPrefix(contentType, grpcWebTextContentType)
+	contentType = strings.TrimPrefix(contentType, grpcWebContentType)
+
+	if strings.HasPrefix(contentType, "+") {
+		return contentType
+	}
+	return ""
+}
+
+func (w *grpcWebResponseWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *grpcWebResponseWriter) WriteHeader(status int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+
+	// announced trailers are sent in the body
+	for _, v := range w.header.Values("Trailer") {
+		for _, name := range strings.Split(v, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				w.trailers = append(w.trailers, name)
+			}
+		}
+	}
+
+	dst := w.ResponseWriter.Header()
+	for k, vv := range w.header {
+		if k == "Trailer" || k == "Content-Length" {
+			continue
+		}
+		dst[k] = vv
+	}
+
+	contentType := grpcWebContentType
+	if w.text {
+		contentType = grpcWebTextContentType
+	}
+	dst.Set("Content-Type", contentType+grpcContentTypeSuffix(w.contentType))
+
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *grpcWebResponseWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	if !w.text {
+		return w.ResponseWriter.Write(b)
+	}
+
+	if _, err := io.WriteString(w.ResponseWriter, base64.StdEncoding.EncodeToString(b)); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+func (w *grpcWebResponseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// finish method writes the trailers frame, trailers-only responses
+// keep the status in the headers.
+func (w *grpcWebResponseWriter) finish() {
+	if !w.wroteHeader {
+		return
+	}
+
+	trailers := make(http.Header)
+	for _, name := range w.trailers {
+		if vv := w.header.Values(name); len(vv) > 0 {
+			trailers[http.CanonicalHeaderKey(name)] = vv
+		}
+	}
+	for k, vv := range w.header {
+		if name, ok := strings.CutPrefix(k, http.TrailerPrefix); ok {
+			trailers[http.CanonicalHeaderKey(name)] = vv
+		}
+	}
+
+	if len(trailers) == 0 {
+		return
+	}
+
+	var payload bytes.Buffer
+	for k, vv := range trailers {
+		for _, v := range vv {
+			payload.WriteString(strings.ToLower(k) + ": " + v + "\r\n")
+		}
+	}
+
+	frame := make([]byte, grpcFrameHeaderLen, grpcFrameHeaderLen+payload.Len())
+	frame[0] = grpcWebTrailerFlag
+	binary.BigEndian.PutUint32(frame[1:], uint32(payload.Len())) //nolint:gosec
+	frame = append(frame, payload.Bytes()...)
+
+	_, _ = w.Write(frame)
+	w.Flush()
+}
+
+func (r *grpcWebTextReader) Read(p []byte) (int, error) {
+	for len(r.out) == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+		r.fill()
+	}
+
+	n := copy(p, r.out)
+	r.out = r.out[n:]
+
+	return n, nil
+}
+
+// fill method decodes the complete base64 quanta read from the body.
+func (r *grpcWebTextReader) fill() {
+	chunk := make([]byte, 4096) //nolint:mnd
+	n, err := r.body.Read(chunk)
+	r.buf = append(r.buf, chunk[:n]...)
+
+	complete := len(r.buf) - len(r.buf)%base64Quantum
+	if err != nil {
+		complete = len(r.buf)
+	}
+
+	if complete > 0 {
+		decoded, decodeErr := decodePaddedBase64(r.buf[:complete])
+		r.buf = r.buf[complete:]
+		if decodeErr != nil {
+			err = decodeErr
+		}
+		r.out = decoded
+	}
+
+	if err != nil {
+		r.err = err
+	}
+}
+
+func (r *grpcWebTextReader) Close() error {
+	return r.body.Close()
+}
+
+// decodePaddedBase64 function decodes base64 data made of several padded chunks.
+func decodePaddedBase64(data []byte) ([]byte, error) {
+	var out []byte
+
+	for len(data) > 0 {
+		end := len(data)
+		if i := bytes.IndexByte(data, '='); i >= 0 {
+			// the padding ends the base64 quantum
+			end = min(i+base64Quantum-i%base64Quantum, len(data))
+		}
+
+		decoded := make([]byte, base64.StdEncoding.DecodedLen(end))
+		n, err := base64.StdEncoding.Decode(decoded, data[:end])
+		if err != nil {
+			return out, err
+		}
+
+		out = append(out, decoded[:n]...)
+		data = data[end:]
+	}
+
+	return out, nil
+}
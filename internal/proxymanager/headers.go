@@ -0,0 +1,192 @@
+// SPDX-FileCopyrightText: 2025 Hector @sudosu404 <hector@email.gnx>
+// SPDX-License-Identifier: AGPL3
+
+package proxymanager
+
+import (
+	"encoding/json"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+	"text/template"
+
+	"github.com/sudosu404/tailnet-lib/internal/identity"
+	"github.com/sudosu404/tailnet-lib/internal/model"
+
+	"github.com/rs/zerolog"
+)
+
+type (
+	// headerRewriter struct applies the header rules of a port.
+	headerRewriter struct {
+		remove []string
+		set    []headerValue
+		add    []headerValue
+	}
+
+	// headerValue struct stores a header value, parsed as template if needed.
+	headerValue struct {
+		tmpl  *template.Template
+		name  string
+		value string
+	}
+
+	// headerData struct is the data available in the header templates.
+	headerData struct {
+		Whois   model.Whois
+		Proxy   headerProxyData
+		Port    headerPortData
+		Request headerRequestData
+	}
+
+	headerProxyData struct {
+		Hostname string
+	}
+
+	headerPortData struct {
+		Name string
+	}
+
+	headerRequestData struct {
+		Host       string
+		Method     string
+		Path       string
+		RemoteAddr string
+	}
+)
+
+// newHeaderRewriter function returns a headerRewriter or nil if there are no rules.
+// Invalid templates are logged and skipped.
+func newHeaderRewriter(rules model.HeaderRules, log zerolog.Logger) *headerRewriter {
+	if rules.IsEmpty() {
+		return nil
+	}
+
+	return &headerRewriter{
+		remove: rules.Remove,
+		set:    parseHeaderValues(rules.Set, log),
+		add:    parseHeaderValues(rules.Add, log),
+	}
+}
+
+// parseHeaderValues function parses the header values sorted by header name.
+func parseHeaderValues(headers map[string]string, log zerolog.Logger) []headerValue {
+	values := make([]headerValue, 0, len(headers))
+
+	for _, name := range slices.Sorted(maps.Keys(headers)) {
+		v := headerValue{name: name, value: headers[name]}
+
+		if strings.Contains(v.value, "{{") {
+			tmpl, err := template.New(name).Option("missingkey=zero").Parse(v.value)
+			if err != nil {
+				log.Error().Err(err).Str("header", name).Msg("invalid header template")
+				continue
+			}
+			v.tmpl = tmpl
+		}
+
+		values = append(values, v)
+	}
+
+	return values
+}
+
+// apply method removes, sets and adds the headers, in this order.
+func (h *headerRewriter) apply(header http.Header, data *headerData) {
+	if h == nil {
+		return
+	}
+
+	for _, name := range h.remove {
+		header.Del(name)
+	}
+
+	for _, v := range h.set {
+		header.Set(v.name, v.render(data))
+	}
+
+	for _, v := range h.add {
+		header.Add(v.name, v.render(data))
+	}
+}
+
+func (v headerValue) render(data *headerData) string {
+	if v.tmpl == nil {
+		return v.value
+	}
+
+	var b strings.Builder
+	if err := v.tmpl.Execute(&b, data); err != nil {
+		return ""
+	}
+
+	return b.String()
+}
+
+// newHeaderData function returns the template data of a request.
+func newHeaderData(r *http.Request, proxyName, portName string) *headerData {
+	who, _ := model.WhoisFromContext(r.Context())
+
+	return &headerData{
+		Whois: who,
+		Proxy: headerProxyData{Hostname: proxyName},
+		Port:  headerPortData{Name: portName},
+		Request: headerRequestData{
+			Host:       r.Host,
+			Method:     r.Method,
+			Path:       r.URL.Path,
+			RemoteAddr: r.RemoteAddr,
+		},
+	}
+}
+
+// setIdentityHeaders function sets the identity headers of the request.
+// Headers with the same names sent by the client are always removed.
+func setIdentityHeaders(header http.Header, config model.IdentityHeaders, who model.Whois, ok bool) {
+	header.Del(config.GetUsername())
+	header.Del(config.GetDisplayName())
+	header.Del(config.GetProfilePicURL())
+	header.Del(config.GetCapabilities())
+
+	if config.Disabled || !ok {
+		return
+	}
+
+	header.Set(config.GetUsername(), who.Username)
+	header.Set(config.GetDisplayName(), who.DisplayName)
+	header.Set(config.GetProfilePicURL(), who.ProfilePicURL)
+
+	if len(who.Capabilities) > 0 {
+		if capabilities, err := json.Marshal(who.Capabilities); err == nil {
+			header.Set(config.GetCapabilities(), string(capabilities))
+		}
+	}
+}
+
+// setIdentityToken function sets the signed identity token of the request,
+// the audience defaults to the proxy hostname.
+// A header with the same name sent by the client is always removed, and requests
+// without a Tailscale user, like funnel requests, don't get a token.
+func setIdentityToken(header http.Header, config model.IdentityToken, proxyName string,
+	who model.Whois, ok bool, log zerolog.Logger,
+) {
+	header.Del(config.GetHeader())
+
+	if !config.Enabled || !ok || who.ID == "" {
+		return
+	}
+
+	audience := config.Audience
+	if audience == "" {
+		audience = proxyName
+	}
+
+	token, err := identity.Sign(identity.NewClaims(who, audience, config.GetTTL()))
+	if err != nil {
+		log.Error().Err(err).Msg("error signing identity token")
+		return
+	}
+
+	header.Set(config.GetHeader(), token)
+}
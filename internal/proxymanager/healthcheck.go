@@ -0,0 +1,198 @@
+// SPDX-FileCopyrightText: 2025 Hector @sudosu404 <hector@email.gnx>
+// SPDX-License-Identifier: AGPL3
+
+package proxymanager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/sudosu404/tailnet-lib/internal/model"
+
+	"github.com/rs/zerolog"
+)
+
+type (
+	// healthChecker struct runs active health checks against the upstreams of a port.
+	healthChecker struct {
+		log       zerolog.Logger
+		client    *http.Client
+		dial      dialFunc
+		onChange  func(u *upstream)
+		upstreams []*upstream
+		config    model.HealthCheck
+	}
+)
+
+var ErrUnhealthyStatusCode = errors.New("unhealthy status code")
+
+// newHealthChecker function returns a healthChecker or nil if the port has no health check.
+func newHealthChecker(pconfig model.PortConfig, upstreams []*upstream, log zerolog.Logger,
+	onChange func(u *upstream),
+) *healthChecker {
+	if !pconfig.HealthCheck.IsEnabled() {
+		return nil
+	}
+
+	tr := &http.Transport{
+		TLSClientConfig:   newUpstreamTLSConfig(pconfig, log),
+		DialContext:       upstreamDialer(pconfig),
+		DisableKeepAlives: true,
+	}
+
+	return &healthChecker{
+		log:       log.With().Str("module", "healthcheck").Logger(),
+		config:    pconfig.HealthCheck,
+		upstreams: upstreams,
+		onChange:  onChange,
+		dial:      upstreamDialer(pconfig),
+		client: &http.Client{
+			Transport: newUpstreamTransport(tr),
+			Timeout:   pconfig.HealthCheck.GetTimeout(),
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}
+}
+
+// run method checks all upstreams on every interval until the context is canceled.
+func (h *healthChecker) run(ctx context.Context) {
+	h.log.Debug().Str("type", h.config.Type).Dur("interval", h.config.GetInterval()).Msg("starting health checks")
+
+	ticker := time.NewTicker(h.config.GetInterval())
+	defer ticker.Stop()
+
+	for {
+		h.checkAll(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (h *healthChecker) checkAll(ctx context.Context) {
+	wg := sync.WaitGroup{}
+
+	for _, u := range h.upstreams {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			h.update(u, h.check(ctx, u))
+		}()
+	}
+
+	wg.Wait()
+}
+
+// update method applies the thresholds and notifies when the upstream health changes.
+func (h *healthChecker) update(u *upstream, err error) {
+	u.mtx.Lock()
+
+	if err != nil {
+		u.successes = 0
+		u.failures++
+	} else {
+		u.failures = 0
+		u.successes++
+	}
+
+	health := u.health
+	switch {
+	case err != nil && u.failures >= h.config.GetUnhealthyThreshold():
+		health = model.TargetHealthUnhealthy
+	case err == nil && (u.successes >= h.config.GetHealthyThreshold() || u.health == model.TargetHealthUnknown):
+		health = model.TargetHealthHealthy
+	}
+
+	changed := health != u.health
+	u.health = health
+	u.mtx.Unlock()
+
+	if !changed {
+		return
+	}
+
+	if err != nil {
+		h.log.Warn().Err(err).Str("target", u.url.String()).Msg("target is unhealthy")
+	} else {
+		h.log.Info().Str("target", u.url.String()).Msg("target is healthy")
+	}
+
+	if h.onChange != nil {
+		h.onChange(u)
+	}
+}
+
+// reportFailure method counts a failed connection to the upstream as a failed check,
+// the upstream is taken out of rotation after the unhealthy threshold and the
+// health checks bring it back. Ports without health checks ignore it.
+func (h *healthChecker) reportFailure(u *upstream, err error) {
+	if h == nil {
+		return
+	}
+	h.update(u, err)
+}
+
+func (h *healthChecker) check(ctx context.Context, u *upstream) error {
+	if h.config.Type == model.HealthCheckTCP {
+		return h.checkTCP(ctx, u)
+	}
+	return h.checkHTTP(ctx, u)
+}
+
+// checkTCP method tries to open a connection to the upstream, with the
+// dialer of the port targets.
+func (h *healthChecker) checkTCP(ctx context.Context, u *upstream) error {
+	address := u.address()
+
+	ctx, cancel := context.WithTimeout(ctx, h.config.GetTimeout())
+	defer cancel()
+
+	conn, err := h.dial(ctx, "tcp", address)
+	if err != nil {
+		return fmt.Errorf("error dialing %s: %w", address, err)
+	}
+	conn.Close()
+
+	return nil
+}
+
+// checkHTTP method sends a GET request to the health check path of the upstream.
+func (h *healthChecker) checkHTTP(ctx context.Context, u *upstream) error {
+	target := u.url.JoinPath(h.config.GetPath())
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target.String(), nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("%w: %d", ErrUnhealthyStatusCode, resp.StatusCode)
+	}
+
+	return nil
+}
+
+// defaultPortForScheme function returns the port of the targets without port,
+// 443 for the schemes with TLS and 80 for the others.
+func defaultPortForScheme(scheme string) string {
+	switch scheme {
+	case "https", "wss", model.SchemeGRPCS, model.ProtocolTLS:
+		return "443"
+	}
+	return "80"
+}
@@ -0,0 +1,84 @@
+// SPDX-FileCopyrightText: 2025 Hector @sudosu404 <hector@email.gnx>
+// SPDX-License-Identifier: AGPL3
+
+package proxymanager
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/sudosu404/tailnet-lib/internal/model"
+
+	"github.com/rs/zerolog"
+)
+
+func TestUpstreamAddress(t *testing.T) {
+	tests := []struct {
+		target string
+		want   string
+	}{
+		{target: "http://app", want: "app:80"},
+		{target: "https://app", want: "app:443"},
+		{target: "h2c://app", want: "app:80"},
+		{target: "grpc://app", want: "app:80"},
+		{target: "grpcs://app", want: "app:443"},
+		{target: "wss://app", want: "app:443"},
+		{target: "tcp://app", want: "app:80"},
+		{target: "tls://app", want: "app:443"},
+		{target: "grpcs://app:8443", want: "app:8443"},
+		{target: "https://[fd7a::1]", want: "[fd7a::1]:443"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			u, err := url.Parse(tt.target)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := (&upstream{url: u}).address(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHealthCheckTCPUsesPortDialer(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	header := make(chan string, 1)
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		line, _ := bufio.NewReader(conn).ReadString('\n')
+		header <- line
+	}()
+
+	pconfig := model.PortConfig{
+		HealthCheck:       model.HealthCheck{Type: model.HealthCheckTCP},
+		SendProxyProtocol: model.ProxyProtocolV1,
+	}
+	hc := newHealthChecker(pconfig, nil, zerolog.Nop(), nil)
+
+	u, _ := url.Parse("tcp://" + l.Addr().String())
+	if err := hc.check(context.Background(), &upstream{url: u}); err != nil {
+		t.Fatal(err)
+	}
+
+	// the targets of the port expect the PROXY protocol header
+	if got := <-header; !strings.HasPrefix(got, "PROXY ") {
+		t.Errorf("got %q, want a PROXY protocol header", got)
+	}
+}
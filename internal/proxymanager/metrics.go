@@ -0,0 +1,208 @@
+// SPDX-FileCopyrightText: 2025 Hector @sudosu404 <hector@email.gnx>
+// SPDX-License-Identifier: AGPL3
+
+package proxymanager
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/sudosu404/tailnet-lib/internal/core"
+	"github.com/sudosu404/tailnet-lib/internal/metrics"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type (
+	// requestInfo struct is shared by the middlewares of a request
+	// to record how the request was handled.
+	requestInfo struct {
+		upstream string
+	}
+
+	// responseRecorder struct records the status and the bytes written of a response.
+	responseRecorder struct {
+		http.ResponseWriter
+		status int
+		bytes  int64
+	}
+
+	// countingReader struct counts the bytes read from the request body.
+	countingReader struct {
+		io.ReadCloser
+		bytes int64
+	}
+
+	// limitCollector struct exports the rate limit counters of all proxies.
+	limitCollector struct {
+		pm   *ProxyManager
+		desc *prometheus.Desc
+	}
+
+	// connCollector struct exports the connection accounting of the passthrough ports.
+	connCollector struct {
+		pm         *ProxyManager
+		activeDesc *prometheus.Desc
+		totalDesc  *prometheus.Desc
+		bytesDesc  *prometheus.Desc
+	}
+)
+
+const contextKeyRequestInfo contextKey = "contextkey.requestinfo"
+
+// metricsMiddleware function records the requests of a port in the metrics.
+func metricsMiddleware(proxyName, portName string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		info := &requestInfo{}
+		rec := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+
+		var body *countingReader
+		if r.Body != nil && r.Body != http.NoBody {
+			body = &countingReader{ReadCloser: r.Body}
+			r.Body = body
+		}
+
+		next.ServeHTTP(rec, r.WithContext(context.WithValue(r.Context(), contextKeyRequestInfo, info)))
+
+		var bytesIn int64
+		if body != nil {
+			bytesIn = body.bytes
+		}
+
+		metrics.ObserveRequest(proxyName, portName, info.upstream, rec.status, time.Since(start), bytesIn, rec.bytes)
+	})
+}
+
+// requestInfoFromContext function returns the requestInfo of the request if it's recorded.
+func requestInfoFromContext(ctx context.Context) (*requestInfo, bool) {
+	info, ok := ctx.Value(contextKeyRequestInfo).(*requestInfo)
+	return info, ok
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	n, err := r.ResponseWriter.Write(b)
+	r.bytes += int64(n)
+	return n, err
+}
+
+// Unwrap method allows http.ResponseController to reach the original ResponseWriter.
+func (r *responseRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+func (r *responseRecorder) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (r *responseRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := r.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, core.ErrHijackNotSupported
+	}
+	return h.Hijack()
+}
+
+func (c *countingReader) Read(b []byte) (int, error) {
+	n, err := c.ReadCloser.Read(b)
+	c.bytes += int64(n)
+	return n, err
+}
+
+func newLimitCollector(pm *ProxyManager) *limitCollector {
+	return &limitCollector{
+		pm: pm,
+		desc: prometheus.NewDesc(
+			"tailnet_ratelimit_requests_total",
+			"Total number of requests checked by the rate limits, by limit scope and result.",
+			[]string{"proxy", "port", "scope", "result"}, nil,
+		),
+	}
+}
+
+// Describe method implements prometheus.Collector.
+func (c *limitCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+// Collect method implements prometheus.Collector.
+func (c *limitCollector) Collect(ch chan<- prometheus.Metric) {
+	c.pm.mtx.RLock()
+	defer c.pm.mtx.RUnlock()
+
+	for name, proxy := range c.pm.Proxies {
+		for port, scopes := range proxy.GetLimitStats() {
+			for scope, stats := range scopes {
+				for result, value := range map[string]uint64{
+					"allowed":             stats.Allowed.Load(),
+					"rate_limited":        stats.RateLimited.Load(),
+					"concurrency_limited": stats.ConcurrencyLimited.Load(),
+				} {
+					ch <- prometheus.MustNewConstMetric(c.desc, prometheus.CounterValue, float64(value),
+						name, port, scope, result)
+				}
+			}
+		}
+	}
+}
+
+func newConnCollector(pm *ProxyManager) *connCollector {
+	labels := []string{"proxy", "port"}
+
+	return &connCollector{
+		pm: pm,
+		activeDesc: prometheus.NewDesc(
+			"tailnet_passthrough_connections_active",
+			"Current number of connections, or udp sessions, of the passthrough ports.",
+			labels, nil,
+		),
+		totalDesc: prometheus.NewDesc(
+			"tailnet_passthrough_connections_total",
+			"Total number of connections, or udp sessions, handled by the passthrough ports.",
+			labels, nil,
+		),
+		bytesDesc: prometheus.NewDesc(
+			"tailnet_passthrough_bytes_total",
+			"Total bytes forwarded by the passthrough ports, by direction.",
+			append(labels, "direction"), nil,
+		),
+	}
+}
+
+// Describe method implements prometheus.Collector.
+func (c *connCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.activeDesc
+	ch <- c.totalDesc
+	ch <- c.bytesDesc
+}
+
+// Collect method implements prometheus.Collector.
+func (c *connCollector) Collect(ch chan<- prometheus.Metric) {
+	c.pm.mtx.RLock()
+	defer c.pm.mtx.RUnlock()
+
+	for name, proxy := range c.pm.Proxies {
+		for port, stats := range proxy.GetConnStats() {
+			ch <- prometheus.MustNewConstMetric(c.activeDesc, prometheus.GaugeValue,
+				float64(stats.active.Load()), name, port)
+			ch <- prometheus.MustNewConstMetric(c.totalDesc, prometheus.CounterValue,
+				float64(stats.total.Load()), name, port)
+			ch <- prometheus.MustNewConstMetric(c.bytesDesc, prometheus.CounterValue,
+				float64(stats.bytesIn.Load()), name, port, "in")
+			ch <- prometheus.MustNewConstMetric(c.bytesDesc, prometheus.CounterValue,
+				float64(stats.bytesOut.Load()), name, port, "out")
+		}
+	}
+}
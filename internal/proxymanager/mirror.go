@@ -0,0 +1,266 @@
+// SPDX-FileCopyrightText: 2025 Hector @sudosu404 <hector@email.gnx>
+// SPDX-License-Identifier: AGPL3
+
+package proxymanager
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/sudosu404/tailnet-lib/internal/metrics"
+	"github.com/sudosu404/tailnet-lib/internal/model"
+
+	"github.com/rs/zerolog"
+)
+
+type (
+	// mirror struct sends a copy of the port requests to a shadow target.
+	// Mirrored requests are sent in the background and their responses are discarded,
+	// they never delay or change the response of the client.
+	mirror struct {
+		ctx       context.Context
+		log       zerolog.Logger
+		target    *url.URL
+		client    *http.Client
+		config    model.Mirror
+		identity  model.IdentityHeaders
+		inflight  chan struct{}
+		proxyName string
+		portName  string
+	}
+
+	// mirrorBody struct keeps a copy of the request body read by the port target.
+	mirrorBody struct {
+		io.ReadCloser
+		buf      bytes.Buffer
+		max      int64
+		overflow bool
+		eof      bool
+		mtx      sync.Mutex
+	}
+)
+
+const (
+	// mirrorMaxInflight limits the mirrored requests in progress of a port,
+	// new requests are dropped while the mirror is slow.
+	mirrorMaxInflight = 100
+
+	mirrorResultSent    = "sent"
+	mirrorResultFailed  = "failed"
+	mirrorResultDropped = "dropped"
+	mirrorResultSkipped = "skipped"
+)
+
+// hopHeaders are not copied to the mirrored requests.
+var hopHeaders = []string{
+	"Connection",
+	"Keep-Alive",
+	"Proxy-Authenticate",
+	"Proxy-Authorization",
+	"Proxy-Connection",
+	"Te",
+	"Trailer",
+	"Transfer-Encoding",
+	"Upgrade",
+}
+
+// newMirror function returns the mirror of a port, nil if the port has no mirror.
+func newMirror(ctx context.Context, pconfig model.PortConfig, proxyName string, log zerolog.Logger) *mirror {
+	if !pconfig.Mirror.IsEnabled() {
+		return nil
+	}
+
+	target, err := url.Parse(pconfig.Mirror.Target)
+	if err != nil || target.Scheme == "" || target.Host == "" {
+		log.Error().Err(err).Str("mirror", pconfig.Mirror.Target).Msg("invalid mirror target URL")
+		return nil
+	}
+
+	// the mirror is another host: it trusts the port CA bundle, but the
+	// server name and the client certificate belong to the port targets
+	mirrorConfig := model.PortConfig{
+		TLSValidate: pconfig.TLSValidate,
+		UpstreamTLS: model.UpstreamTLS{CAFile: pconfig.UpstreamTLS.CAFile},
+	}
+	mirrorConfig.Timeouts.Dial = pconfig.Mirror.GetTimeout()
+
+	tr := &http.Transport{
+		TLSClientConfig:     newUpstreamTLSConfig(mirrorConfig, log),
+		DialContext:         upstreamDialer(mirrorConfig),
+		MaxIdleConnsPerHost: mirrorMaxInflight,
+	}
+
+	return &mirror{
+		ctx:    ctx,
+		log:    log.With().Str("mirror", target.String()).Logger(),
+		target: target,
+		client: &http.Client{
+			Transport: newUpstreamTransport(tr),
+			Timeout:   pconfig.Mirror.GetTimeout(),
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		config:    pconfig.Mirror,
+		identity:  pconfig.Headers.Identity,
+		inflight:  make(chan struct{}, mirrorMaxInflight),
+		proxyName: proxyName,
+		portName:  pconfig.String(),
+	}
+}
+
+// middleware method copies the sampled requests to the mirror. Requests without
+// body are mirrored at once, the others after the port target read the body.
+func (m *mirror) middleware(next http.Handler) http.Handler {
+	if m == nil {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if rand.Float64()*100 >= m.config.GetPercent() { //nolint:gosec,mnd
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		// upgraded connections, like websockets, can't be mirrored
+		if r.Header.Get("Upgrade") != "" {
+			m.count(mirrorResultSkipped)
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if r.Body == nil || r.Body == http.NoBody {
+			m.send(r, nil)
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if r.ContentLength > m.config.GetMaxBodySize() {
+			m.count(mirrorResultSkipped)
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		body := &mirrorBody{ReadCloser: r.Body, max: m.config.GetMaxBodySize()}
+		r.Body = body
+
+		next.ServeHTTP(w, r)
+
+		content, ok := body.content()
+		if !ok {
+			m.log.Debug().Str("path", r.URL.Path).Msg("request body not mirrored")
+			m.count(mirrorResultSkipped)
+			return
+		}
+
+		m.send(r, content)
+	})
+}
+
+// send method sends a copy of the request in the background.
+func (m *mirror) send(r *http.Request, body []byte) {
+	select {
+	case m.inflight <- struct{}{}:
+	default:
+		m.count(mirrorResultDropped)
+		return
+	}
+
+	out := m.newRequest(r, body)
+
+	go func() {
+		defer func() { <-m.inflight }()
+
+		resp, err := m.client.Do(out)
+		if err != nil {
+			m.log.Warn().Err(err).Str("path", r.URL.Path).Msg("mirror request failed")
+			m.count(mirrorResultFailed)
+			return
+		}
+
+		_, _ = io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+
+		m.count(mirrorResultSent)
+	}()
+}
+
+// newRequest method returns the copy of a request sent to the mirror,
+// with the same headers the port target receives.
+func (m *mirror) newRequest(r *http.Request, body []byte) *http.Request {
+	u := *m.target
+	u.Path = strings.TrimSuffix(m.target.Path, "/") + r.URL.Path
+	u.RawPath = ""
+	u.RawQuery = r.URL.RawQuery
+
+	out := r.Clone(m.ctx)
+	out.URL = &u
+	out.RequestURI = ""
+	out.Host = r.Host
+	out.Body = http.NoBody
+	out.ContentLength = 0
+	out.TransferEncoding = nil
+	out.Close = false
+
+	if len(body) > 0 {
+		out.Body = io.NopCloser(bytes.NewReader(body))
+		out.ContentLength = int64(len(body))
+	}
+
+	for _, h := range hopHeaders {
+		out.Header.Del(h)
+	}
+
+	user, ok := model.WhoisFromContext(r.Context())
+	setIdentityHeaders(out.Header, m.identity, user, ok)
+
+	pr := &httputil.ProxyRequest{In: r, Out: out}
+	pr.SetXForwarded()
+
+	return out
+}
+
+func (m *mirror) count(result string) {
+	metrics.IncMirrorRequest(m.proxyName, m.portName, result)
+}
+
+func (b *mirrorBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	if !b.overflow {
+		if int64(b.buf.Len()+n) > b.max {
+			b.overflow = true
+			b.buf = bytes.Buffer{}
+		} else {
+			b.buf.Write(p[:n])
+		}
+	}
+
+	if err == io.EOF {
+		b.eof = true
+	}
+
+	return n, err
+}
+
+// content method returns the body if it was read completely and fits the mirror limit.
+func (b *mirrorBody) content() ([]byte, bool) {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	if !b.eof || b.overflow {
+		return nil, false
+	}
+
+	return bytes.Clone(b.buf.Bytes()), true
+}
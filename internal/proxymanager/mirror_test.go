@@ -0,0 +1,83 @@
+// SPDX-FileCopyrightText: 2025 Hector @sudosu404 <hector@email.gnx>
+// SPDX-License-Identifier: AGPL3
+
+package proxymanager
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sudosu404/tailnet-lib/internal/model"
+
+	"github.com/rs/zerolog"
+)
+
+func TestMirrorH2CTarget(t *testing.T) {
+	received := make(chan string, 1)
+
+	shadow := httptest.NewUnstartedServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		received <- r.Proto + " " + r.URL.Path
+	}))
+	shadow.Config.Protocols = new(http.Protocols)
+	shadow.Config.Protocols.SetUnencryptedHTTP2(true)
+	shadow.Start()
+	defer shadow.Close()
+
+	pconfig := model.PortConfig{
+		Mirror: model.Mirror{Target: strings.Replace(shadow.URL, "http://", "h2c://", 1)},
+		// the port targets settings are not used by the mirror
+		UpstreamTLS: model.UpstreamTLS{ServerName: "port-target.internal"},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	m := newMirror(ctx, pconfig, "proxy", zerolog.Nop())
+	if m == nil {
+		t.Fatal("mirror not created")
+	}
+
+	handler := m.middleware(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/items", nil))
+
+	select {
+	case got := <-received:
+		if got != "HTTP/2.0 /api/items" {
+			t.Errorf("got %q, want %q", got, "HTTP/2.0 /api/items")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("request not mirrored")
+	}
+}
+
+func TestMirrorNewRequest(t *testing.T) {
+	m := newMirror(context.Background(), model.PortConfig{
+		Mirror: model.Mirror{Target: "http://shadow:8080/base/"},
+	}, "proxy", zerolog.Nop())
+
+	r := httptest.NewRequest(http.MethodPost, "http://app.example.com/items?id=1", nil)
+	r.Header.Set("Connection", "keep-alive")
+	r.Header.Set("X-Custom", "value")
+
+	out := m.newRequest(r, []byte("body"))
+
+	if got := out.URL.String(); got != "http://shadow:8080/base/items?id=1" {
+		t.Errorf("url: got %q", got)
+	}
+	if out.Host != "app.example.com" {
+		t.Errorf("host: got %q", out.Host)
+	}
+	if out.Header.Get("Connection") != "" {
+		t.Error("hop header copied")
+	}
+	if out.Header.Get("X-Custom") != "value" {
+		t.Error("header not copied")
+	}
+	if out.ContentLength != 4 {
+		t.Errorf("content length: got %d", out.ContentLength)
+	}
+}
@@ -5,7 +5,6 @@ package proxymanager
 
 import (
 	"context"
-	"crypto/tls"
 	"errors"
 	"fmt"
 	"net"
@@ -13,75 +12,218 @@ import (
 	"net/http/httputil"
 	"sync"
 
-	"github.com/sudosu404/tailnet-lib/internal/consts"
+	"github.com/sudosu404/tailnet-lib/internal/accesslog"
 	"github.com/sudosu404/tailnet-lib/internal/core"
+	"github.com/sudosu404/tailnet-lib/internal/identity"
 	"github.com/sudosu404/tailnet-lib/internal/model"
 
 	"github.com/rs/zerolog"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 )
 
-type port struct {
-	log        zerolog.Logger
-	ctx        context.Context
-	listener   net.Listener
-	cancel     context.CancelFunc
-	httpServer *http.Server
-	mtx        sync.Mutex
-}
+type (
+	port struct {
+		log          zerolog.Logger
+		ctx          context.Context
+		listener     net.Listener
+		cancel       context.CancelFunc
+		server       server
+		packetServer *udpProxy
+		packetConns  []net.PacketConn
+		upstreams    []*upstream
+		healthCheck  *healthChecker
+		limiter      *rateLimiter
+		canary       *canary
+		mtx          sync.Mutex
+	}
+
+	// server interface is implemented by the handlers of port connections.
+	server interface {
+		Serve(l net.Listener) error
+		Shutdown(ctx context.Context) error
+	}
+)
 
 func newPortProxy(
 	ctx context.Context,
 	pconfig model.PortConfig,
+	proxyConfig *model.Config,
 	log zerolog.Logger,
-	accessLog bool,
 	whoisFunc func(next http.Handler) http.Handler,
+	onTargetHealth func(target string, health model.TargetHealth),
 ) *port {
 	//
 	log = log.With().Str("port", pconfig.String()).Logger()
 
 	ctxPort, cancel := context.WithCancel(ctx)
 
+	if pconfig.IdentityToken.Enabled {
+		if err := identity.LoadKey(); err != nil {
+			log.Error().Err(err).Msg("error loading identity signing key")
+		}
+	}
+
 	// Create the reverse proxy
 	//
 	tr := &http.Transport{
-		TLSClientConfig: &tls.Config{InsecureSkipVerify: !pconfig.TLSValidate}, //nolint
+		TLSClientConfig:       newUpstreamTLSConfig(pconfig, log),
+		DialContext:           upstreamDialer(pconfig),
+		ResponseHeaderTimeout: pconfig.Timeouts.ResponseHeader,
+		// the PROXY protocol header is sent once per connection, each request needs its own
+		DisableKeepAlives: pconfig.SendProxyProtocol != "",
 	}
+	lb := newBalancer(pconfig)
+
+	requestHeaders := newHeaderRewriter(pconfig.Headers.Request, log)
+	responseHeaders := newHeaderRewriter(pconfig.Headers.Response, log)
+
 	reverseProxy := &httputil.ReverseProxy{
-		Transport: tr,
+		// the transport injects the trace context in the upstream requests
+		Transport: otelhttp.NewTransport(newUpstreamTransport(tr)),
 		Rewrite: func(r *httputil.ProxyRequest) {
-			r.SetURL(pconfig.GetFirstTarget())
+			target := pconfig.GetFirstTarget()
+			if u, ok := upstreamFromContext(r.In.Context()); ok {
+				target = u.url
+			}
+
+			r.SetURL(target)
+			accesslog.SetUpstream(r.In.Context(), target.String())
 			r.Out.Host = r.In.Host
 			r.Out.Header["X-Forwarded-For"] = r.In.Header["X-Forwarded-For"]
 
-			if user, ok := model.WhoisFromContext(r.In.Context()); ok {
-				r.Out.Header.Set(consts.HeaderUsername, user.Username)
-				r.Out.Header.Set(consts.HeaderDisplayName, user.DisplayName)
-				r.Out.Header.Set(consts.HeaderProfilePicURL, user.ProfilePicURL)
-			}
+			user, ok := model.WhoisFromContext(r.In.Context())
+			setIdentityHeaders(r.Out.Header, pconfig.Headers.Identity, user, ok)
+			setIdentityToken(r.Out.Header, pconfig.IdentityToken, proxyConfig.Hostname, user, ok, log)
 
 			r.SetXForwarded()
+
+			if requestHeaders != nil {
+				requestHeaders.apply(r.Out.Header, newHeaderData(r.In, proxyConfig.Hostname, pconfig.String()))
+			}
 		},
 	}
 
-	handler := whoisFunc(reverseProxy)
-	// add logger to proxy
-	if accessLog {
-		handler = core.LoggerMiddleware(log, handler)
+	reverseProxy.ErrorHandler = func(w http.ResponseWriter, r *http.Request, err error) {
+		statusCode := upstreamErrorStatus(r, err)
+
+		if errors.Is(err, context.Canceled) {
+			log.Debug().Err(err).Str("path", r.URL.Path).Msg("request canceled by the client")
+		} else {
+			log.Error().Err(err).Str("path", r.URL.Path).Int("status", statusCode).Msg("upstream error")
+		}
+
+		renderErrorPage(w, r, statusCode, "")
+	}
+
+	if responseHeaders != nil {
+		reverseProxy.ModifyResponse = func(resp *http.Response) error {
+			responseHeaders.apply(resp.Header, newHeaderData(resp.Request, proxyConfig.Hostname, pconfig.String()))
+			return nil
+		}
 	}
 
+	rt := newRouter(pconfig, lb, log)
+	limiter := newRateLimiter(pconfig.RateLimits, log)
+
+	mirror := newMirror(ctxPort, pconfig, proxyConfig.Hostname, log)
+	canary := newCanary(pconfig, log)
+
+	proxyHandler := grpcWebMiddleware(proxyHeaderMiddleware(pconfig.SendProxyProtocol, reverseProxy))
+	upstreamHandler := mirror.middleware(rt.middleware(proxyHandler, canary))
+	if pconfig.IsFileServer() {
+		upstreamHandler = newFileServer(pconfig, proxyConfig.Hostname, responseHeaders, log)
+	}
+
+	handler := whoisFunc(accessMiddleware(pconfig.AccessPolicy, log, limiter.middleware(compressionMiddleware(pconfig.Compression, requestLimitsMiddleware(pconfig, upstreamHandler)))))
+	// add the access log to proxy
+	if proxyConfig.ProxyAccessLog {
+		accessLog, err := accesslog.Open(proxyConfig.AccessLog)
+		if err != nil {
+			log.Error().Err(err).Msg("error opening access log")
+		} else {
+			handler = accessLog.Middleware(proxyConfig.Hostname, pconfig.String(), log, handler)
+		}
+	}
+
+	// h2c is accepted on http ports for gRPC clients
+	protocols := new(http.Protocols)
+	protocols.SetHTTP1(true)
+	protocols.SetHTTP2(true)
+	protocols.SetUnencryptedHTTP2(true)
+
 	// main http Server
 	httpServer := &http.Server{
 		Handler:           handler,
 		ReadHeaderTimeout: core.ReadHeaderTimeout,
+		IdleTimeout:       pconfig.Timeouts.Idle,
 		BaseContext:       func(net.Listener) context.Context { return ctxPort },
+		Protocols:         protocols,
 	}
 
+	upstreams := append(rt.upstreams(), canary.upstreams()...)
+
 	return &port{
-		log:        log,
-		ctx:        ctxPort,
-		cancel:     cancel,
-		httpServer: httpServer,
+		log:         log,
+		ctx:         ctxPort,
+		cancel:      cancel,
+		server:      httpServer,
+		upstreams:   upstreams,
+		healthCheck: newPortHealthChecker(pconfig, upstreams, log, onTargetHealth),
+		limiter:     limiter,
+		canary:      canary,
+	}
+}
+
+// newPortPassthrough function returns a port that forwards raw tcp or udp traffic.
+func newPortPassthrough(
+	ctx context.Context,
+	pconfig model.PortConfig,
+	proxyConfig *model.Config,
+	log zerolog.Logger,
+	whoisAddr func(ctx context.Context, remoteAddr string) model.Whois,
+	onTargetHealth func(target string, health model.TargetHealth),
+) *port {
+	log = log.With().Str("port", pconfig.String()).Logger()
+
+	ctxPort, cancel := context.WithCancel(ctx)
+
+	lb := newBalancer(pconfig)
+	upstreams := lb.upstreams
+
+	var sni *sniRouter
+	if pconfig.ProxyProtocol == model.ProtocolTLS {
+		sni = newSNIRouter(pconfig, lb)
+		upstreams = sni.upstreams()
+	}
+
+	p := &port{
+		log:         log,
+		ctx:         ctxPort,
+		cancel:      cancel,
+		upstreams:   upstreams,
+		healthCheck: newPortHealthChecker(pconfig, upstreams, log, onTargetHealth),
+	}
+
+	access := newConnAccess(pconfig, proxyConfig, log, whoisAddr)
+
+	if pconfig.ProxyProtocol == model.ProtocolUDP {
+		p.packetServer = newUDPProxy(pconfig, lb, p.healthCheck, log, access)
+	} else {
+		p.server = newTCPProxy(pconfig, lb, sni, p.healthCheck, log, access)
 	}
+
+	return p
+}
+
+// newPortHealthChecker function returns the health checker of a port upstreams.
+func newPortHealthChecker(pconfig model.PortConfig, upstreams []*upstream, log zerolog.Logger,
+	onTargetHealth func(target string, health model.TargetHealth),
+) *healthChecker {
+	return newHealthChecker(pconfig, upstreams, log, func(u *upstream) {
+		if onTargetHealth != nil {
+			onTargetHealth(u.url.String(), u.getHealth())
+		}
+	})
 }
 
 func newPortRedirect(ctx context.Context, pconfig model.PortConfig, log zerolog.Logger) *port {
@@ -91,16 +233,14 @@ func newPortRedirect(ctx context.Context, pconfig model.PortConfig, log zerolog.
 
 	redirectHTTPServer := &http.Server{
 		ReadHeaderTimeout: core.ReadHeaderTimeout,
-		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			http.Redirect(w, r, pconfig.GetFirstTarget().String(), http.StatusMovedPermanently)
-		}),
+		Handler:           newRedirectHandler(pconfig, log),
 	}
 
 	return &port{
-		log:        log,
-		ctx:        ctxPort,
-		cancel:     cancel,
-		httpServer: redirectHTTPServer,
+		log:    log,
+		ctx:    ctxPort,
+		cancel: cancel,
+		server: redirectHTTPServer,
 	}
 }
 
@@ -109,7 +249,11 @@ func (p *port) startWithListener(l net.Listener) error {
 	p.listener = l
 	p.mtx.Unlock()
 
-	err := p.httpServer.Serve(l)
+	if p.healthCheck != nil {
+		go p.healthCheck.run(p.ctx)
+	}
+
+	err := p.server.Serve(l)
 	defer p.log.Info().Msg("Terminating server")
 
 	if err != nil && !errors.Is(err, net.ErrClosed) && !errors.Is(err, http.ErrServerClosed) {
@@ -118,15 +262,86 @@ func (p *port) startWithListener(l net.Listener) error {
 	return nil
 }
 
+func (p *port) startWithPacketConns(pcs []net.PacketConn) error {
+	p.mtx.Lock()
+	p.packetConns = pcs
+	p.mtx.Unlock()
+
+	if p.healthCheck != nil {
+		go p.healthCheck.run(p.ctx)
+	}
+
+	defer p.log.Info().Msg("Terminating server")
+
+	errChan := make(chan error, len(pcs))
+	for _, pc := range pcs {
+		go func() {
+			errChan <- p.packetServer.ServePacket(pc)
+		}()
+	}
+
+	var errs error
+	for range pcs {
+		if err := <-errChan; err != nil && !errors.Is(err, net.ErrClosed) {
+			errs = errors.Join(errs, err)
+		}
+	}
+
+	if errs != nil {
+		return fmt.Errorf("error starting port %w", errs)
+	}
+	return nil
+}
+
+// targetsHealth method returns the health of each target of the port.
+func (p *port) targetsHealth() map[string]model.TargetHealth {
+	health := make(map[string]model.TargetHealth)
+
+	for _, u := range p.upstreams {
+		health[u.url.String()] = u.getHealth()
+	}
+
+	return health
+}
+
+// connStats method returns the connection accounting of passthrough ports, nil for other ports.
+func (p *port) connStats() *connStats {
+	if p.packetServer != nil {
+		return &p.packetServer.stats
+	}
+
+	if t, ok := p.server.(*tcpProxy); ok {
+		return &t.stats
+	}
+
+	return nil
+}
+
 func (p *port) close() error {
+	return p.shutdown(p.ctx)
+}
+
+// shutdown method closes the port, the active requests have until ctx is done to complete.
+func (p *port) shutdown(ctx context.Context) error {
 	var errs error
 
-	if p.httpServer != nil {
-		errs = errors.Join(errs, p.httpServer.Shutdown(p.ctx))
+	if p.server != nil {
+		errs = errors.Join(errs, p.server.Shutdown(ctx))
+	}
+
+	if p.packetServer != nil {
+		errs = errors.Join(errs, p.packetServer.Shutdown(ctx))
 	}
 
+	// the server may have closed the listener already
 	if p.listener != nil {
-		errs = errors.Join(errs, p.listener.Close())
+		if err := p.listener.Close(); err != nil && !errors.Is(err, net.ErrClosed) {
+			errs = errors.Join(errs, err)
+		}
+	}
+
+	for _, pc := range p.packetConns {
+		errs = errors.Join(errs, pc.Close())
 	}
 
 	p.cancel()
@@ -10,8 +10,15 @@ import (
 	"net"
 	"net/http"
 	"net/url"
+	"reflect"
+	"slices"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
+	"github.com/sudosu404/tailnet-lib/internal/accesslog"
+	"github.com/sudosu404/tailnet-lib/internal/metrics"
 	"github.com/sudosu404/tailnet-lib/internal/model"
 	"github.com/sudosu404/tailnet-lib/internal/proxyproviders"
 
@@ -32,9 +39,18 @@ type (
 		ports         map[string]*port
 		mtx           sync.RWMutex
 		status        model.ProxyStatus
+		maintenance   atomic.Pointer[model.Maintenance]
+		// started is set once the proxy provider started the node,
+		// the ports can be changed on a started proxy without restarting it.
+		started atomic.Bool
+		// reloadMtx serializes the changes of ports on a running proxy.
+		reloadMtx sync.Mutex
 	}
 )
 
+// portDrainTimeout is the time the active requests of a stopped port have to complete.
+const portDrainTimeout = 10 * time.Second
+
 // NewProxy function is a function that creates a new proxy.
 func NewProxy(log zerolog.Logger,
 	pcfg *model.Config,
@@ -71,6 +87,7 @@ func NewProxy(log zerolog.Logger,
 		ports:         make(map[string]*port),
 	}
 
+	p.SetMaintenance(pcfg.Maintenance)
 	p.initPorts()
 
 	return p, nil
@@ -116,6 +133,7 @@ func (proxy *Proxy) GetAuthURL() string {
 func (proxy *Proxy) ProviderUserMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		who := proxy.providerProxy.Whois(r)
+		accesslog.SetWhois(r.Context(), who)
 
 		ctx := model.WhoisNewContext(r.Context(), who)
 
@@ -123,22 +141,190 @@ func (proxy *Proxy) ProviderUserMiddleware(next http.Handler) http.Handler {
 	})
 }
 
-func (proxy *Proxy) initPorts() {
-	var newPort *port
-	for k, v := range proxy.Config.Ports {
-		log := proxy.log.With().Str("port", k).Logger()
-		if v.IsRedirect {
-			newPort = newPortRedirect(proxy.ctx, v, log)
-		} else {
-			newPort = newPortProxy(proxy.ctx, v, log, proxy.Config.ProxyAccessLog, proxy.ProviderUserMiddleware)
+// portMiddleware method traces and records the metrics of the port requests,
+// resolves their Whois and applies the proxy access policy.
+func (proxy *Proxy) portMiddleware(pcfg *model.Config, portName string,
+	log zerolog.Logger,
+) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return tracingMiddleware(pcfg.Hostname, portName,
+			metricsMiddleware(pcfg.Hostname, portName,
+				errorPagesMiddleware(pcfg.ErrorPages, proxy.maintenanceMiddleware(
+					proxy.ProviderUserMiddleware(spanWhoisMiddleware(
+						accessMiddleware(pcfg.AccessPolicy, log, next)))))))
+	}
+}
+
+// maintenanceMiddleware method serves the maintenance page while the proxy is in maintenance mode.
+func (proxy *Proxy) maintenanceMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if m := proxy.GetMaintenance(); m.Enabled {
+			renderMaintenancePage(w, r, m)
+			return
 		}
 
-		proxy.log.Debug().Any("port", newPort).Msg("newport")
+		next.ServeHTTP(w, r)
+	})
+}
 
-		proxy.mtx.Lock()
-		proxy.ports[k] = newPort
-		proxy.mtx.Unlock()
+// SetMaintenance method changes the maintenance mode of the proxy, the ports are not restarted.
+func (proxy *Proxy) SetMaintenance(m model.Maintenance) {
+	proxy.maintenance.Store(&m)
+}
+
+// GetMaintenance method returns the current maintenance mode of the proxy.
+func (proxy *Proxy) GetMaintenance() model.Maintenance {
+	if m := proxy.maintenance.Load(); m != nil {
+		return *m
+	}
+	return model.Maintenance{}
+}
+
+// GetTargetsHealth method returns the health of each target grouped by port.
+func (proxy *Proxy) GetTargetsHealth() map[string]map[string]model.TargetHealth {
+	proxy.mtx.RLock()
+	defer proxy.mtx.RUnlock()
+
+	health := make(map[string]map[string]model.TargetHealth, len(proxy.ports))
+	for name, p := range proxy.ports {
+		health[name] = p.targetsHealth()
+	}
+
+	return health
+}
+
+// GetLimitStats method returns the rate limit counters of each limit scope grouped by port,
+// the port is the port name, like "443/https".
+func (proxy *Proxy) GetLimitStats() map[string]map[string]*LimitStats {
+	proxy.mtx.RLock()
+	defer proxy.mtx.RUnlock()
+
+	stats := make(map[string]map[string]*LimitStats)
+	for name, p := range proxy.ports {
+		if s := p.limiter.stats(); s != nil {
+			pconfig := proxy.Config.Ports[name]
+			stats[pconfig.String()] = s
+		}
+	}
+
+	return stats
+}
+
+// GetConnStats method returns the connection accounting of the passthrough ports,
+// the port is the port name, like "5432/tcp".
+func (proxy *Proxy) GetConnStats() map[string]*connStats {
+	proxy.mtx.RLock()
+	defer proxy.mtx.RUnlock()
+
+	stats := make(map[string]*connStats)
+	for name, p := range proxy.ports {
+		if s := p.connStats(); s != nil {
+			pconfig := proxy.Config.Ports[name]
+			stats[pconfig.String()] = s
+		}
 	}
+
+	return stats
+}
+
+// GetCanaries method returns the canary state of the ports with a canary target.
+func (proxy *Proxy) GetCanaries() []CanaryState {
+	proxy.mtx.RLock()
+	defer proxy.mtx.RUnlock()
+
+	canaries := []CanaryState{}
+	for name, p := range proxy.ports {
+		if p.canary == nil {
+			continue
+		}
+
+		pconfig := proxy.Config.Ports[name]
+		canaries = append(canaries, CanaryState{
+			Port:   pconfig.String(),
+			Target: pconfig.Canary.Target,
+			Weight: p.canary.getWeight(),
+		})
+	}
+
+	slices.SortFunc(canaries, func(a, b CanaryState) int { return strings.Compare(a.Port, b.Port) })
+
+	return canaries
+}
+
+// SetCanaryWeight method changes the canary weight of a port, the ports are not restarted.
+// The port is the port name, like "443/https".
+func (proxy *Proxy) SetCanaryWeight(portName string, weight float64) error {
+	proxy.mtx.RLock()
+	defer proxy.mtx.RUnlock()
+
+	for name, p := range proxy.ports {
+		pconfig := proxy.Config.Ports[name]
+		if pconfig.String() != portName && name != portName {
+			continue
+		}
+
+		if p.canary == nil {
+			return ErrCanaryNotFound
+		}
+
+		p.canary.setWeight(weight)
+
+		return nil
+	}
+
+	return ErrCanaryNotFound
+}
+
+// onTargetHealth method returns a function that broadcasts target health changes of a port.
+// The port label is captured when the port is built, the ports config can change while it runs.
+func (proxy *Proxy) onTargetHealth(portName, portLabel string) func(target string, health model.TargetHealth) {
+	return func(target string, health model.TargetHealth) {
+		metrics.SetTargetHealth(proxy.Config.Hostname, portLabel, target, health)
+
+		if proxy.onUpdate == nil {
+			return
+		}
+
+		proxy.onUpdate(model.ProxyEvent{
+			ID:           proxy.Config.Hostname,
+			Port:         portName,
+			Target:       target,
+			Status:       proxy.GetStatus(),
+			TargetHealth: health,
+		})
+	}
+}
+
+func (proxy *Proxy) initPorts() {
+	for k, v := range proxy.GetConfig().Ports {
+		proxy.initPort(k, v)
+	}
+}
+
+// initPort method creates a port from its configuration.
+func (proxy *Proxy) initPort(name string, pconfig model.PortConfig) {
+	var newPort *port
+
+	// the port keeps the proxy settings it was built with
+	pcfg := proxy.GetConfig()
+
+	log := proxy.log.With().Str("port", name).Logger()
+	switch {
+	case pconfig.IsRedirect:
+		newPort = newPortRedirect(proxy.ctx, pconfig, log)
+	case pconfig.IsPassthrough():
+		newPort = newPortPassthrough(proxy.ctx, pconfig, &pcfg, log,
+			proxy.providerProxy.WhoisAddr, proxy.onTargetHealth(name, pconfig.String()))
+	default:
+		newPort = newPortProxy(proxy.ctx, pconfig, &pcfg, log, proxy.portMiddleware(&pcfg, pconfig.String(), log),
+			proxy.onTargetHealth(name, pconfig.String()))
+	}
+
+	proxy.log.Debug().Any("port", newPort).Msg("newport")
+
+	proxy.mtx.Lock()
+	proxy.ports[name] = newPort
+	proxy.mtx.Unlock()
 }
 
 // Start method is a method that starts the proxy.
@@ -146,7 +332,6 @@ func (proxy *Proxy) start() {
 	proxy.log.Info().Msg("starting proxy")
 
 	proxy.mtx.RLock()
-	portsConfig := proxy.Config.Ports
 	portsCount := len(proxy.ports)
 	proxy.mtx.RUnlock()
 
@@ -163,20 +348,163 @@ func (proxy *Proxy) start() {
 		return
 	}
 
-	var l net.Listener
-	var err error
+	// the ports changed while the node was starting are listened with their new configuration
+	proxy.reloadMtx.Lock()
+	defer proxy.reloadMtx.Unlock()
+
+	proxy.started.Store(true)
+
+	for k, v := range proxy.GetConfig().Ports {
+		proxy.listenPort(k, v)
+	}
+}
 
-	for k := range portsConfig {
-		proxy.log.Debug().Str("port", k).Msg("Starting proxy port")
+// listenPort method gets the listener of a port from the proxy provider and starts the port.
+func (proxy *Proxy) listenPort(name string, pconfig model.PortConfig) {
+	proxy.log.Debug().Str("port", name).Msg("Starting proxy port")
 
-		l, err = proxy.providerProxy.GetListener(k)
+	if pconfig.ProxyProtocol == model.ProtocolUDP {
+		pcs, err := proxy.providerProxy.GetPacketListeners(name)
 		if err != nil {
-			proxy.log.Error().Err(err).Str("port", k).Msg("Error adding listener")
-			continue
+			proxy.log.Error().Err(err).Str("port", name).Msg("Error adding packet listener")
+			return
 		}
 
-		proxy.startPort(k, l)
+		proxy.startPacketPort(name, pcs)
+		return
+	}
+
+	l, err := proxy.providerProxy.GetListener(name)
+	if err != nil {
+		proxy.log.Error().Err(err).Str("port", name).Msg("Error adding listener")
+		return
+	}
+
+	proxy.startPort(name, l)
+}
+
+// GetConfig method returns a copy of the current configuration of the proxy,
+// UpdateConfig can change it while the proxy runs.
+func (proxy *Proxy) GetConfig() model.Config {
+	proxy.mtx.RLock()
+	defer proxy.mtx.RUnlock()
+
+	return *proxy.Config
+}
+
+// IsStarted method returns true once the node of the proxy is started.
+func (proxy *Proxy) IsStarted() bool {
+	return proxy.started.Load()
+}
+
+// UpdateConfig method applies a new configuration to the running proxy, the ports
+// are not restarted. The Tailscale settings are kept, they require a new node.
+func (proxy *Proxy) UpdateConfig(pcfg *model.Config) {
+	proxy.mtx.Lock()
+	maintenanceChanged := !reflect.DeepEqual(proxy.Config.Maintenance, pcfg.Maintenance)
+
+	// the proxy provider shares the config, the ports map is replaced and never modified
+	proxy.Config.Ports = pcfg.Ports
+	proxy.Config.Dashboard = pcfg.Dashboard
+	proxy.Config.ProxyAccessLog = pcfg.ProxyAccessLog
+	proxy.Config.AccessLog = pcfg.AccessLog
+	proxy.Config.AccessPolicy = pcfg.AccessPolicy
+	proxy.Config.ErrorPages = pcfg.ErrorPages
+	proxy.Config.Maintenance = pcfg.Maintenance
+	proxy.mtx.Unlock()
+
+	// keep the maintenance mode set at runtime unless the configuration changed it
+	if maintenanceChanged {
+		proxy.SetMaintenance(pcfg.Maintenance)
+	}
+}
+
+// UpdatePendingPort method applies a new configuration to a proxy whose node isn't
+// started yet and rebuilds the port, the pending start listens it. It returns
+// false if the node is already started, the port must be changed with StartPort
+// or StopPort.
+func (proxy *Proxy) UpdatePendingPort(name string, pcfg *model.Config) bool {
+	proxy.reloadMtx.Lock()
+	defer proxy.reloadMtx.Unlock()
+
+	if proxy.IsStarted() {
+		return false
+	}
+
+	proxy.mtx.Lock()
+	p, ok := proxy.ports[name]
+	delete(proxy.ports, name)
+	proxy.mtx.Unlock()
+
+	if ok {
+		// the port isn't listening yet, only its health checks are running
+		_ = p.shutdown(proxy.ctx)
+	}
+
+	proxy.UpdateConfig(pcfg)
+
+	if pconfig, ok := proxy.GetConfig().Ports[name]; ok {
+		proxy.initPort(name, pconfig)
 	}
+
+	return true
+}
+
+// StartPort method starts a port of the configuration on the running node.
+func (proxy *Proxy) StartPort(name string) error {
+	proxy.reloadMtx.Lock()
+	defer proxy.reloadMtx.Unlock()
+
+	return proxy.startConfigPort(name)
+}
+
+// StopPort method stops a port, the other ports and the node keep running.
+func (proxy *Proxy) StopPort(name string) error {
+	proxy.reloadMtx.Lock()
+	defer proxy.reloadMtx.Unlock()
+
+	return proxy.stopPort(name)
+}
+
+func (proxy *Proxy) startConfigPort(name string) error {
+	proxy.mtx.RLock()
+	pconfig, ok := proxy.Config.Ports[name]
+	_, running := proxy.ports[name]
+	proxy.mtx.RUnlock()
+
+	switch {
+	case !ok:
+		return fmt.Errorf("%w: %s", ErrPortNotFound, name)
+	case running:
+		return fmt.Errorf("%w: %s", ErrPortRunning, name)
+	}
+
+	proxy.initPort(name, pconfig)
+	proxy.listenPort(name, pconfig)
+
+	return nil
+}
+
+func (proxy *Proxy) stopPort(name string) error {
+	proxy.mtx.Lock()
+	p, ok := proxy.ports[name]
+	pconfig := proxy.Config.Ports[name]
+	delete(proxy.ports, name)
+	proxy.mtx.Unlock()
+
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrPortNotFound, name)
+	}
+
+	proxy.log.Info().Str("port", name).Msg("stopping port")
+
+	ctx, cancel := context.WithTimeout(proxy.ctx, portDrainTimeout)
+	defer cancel()
+
+	err := p.shutdown(ctx)
+	metrics.DeletePortTargets(proxy.Config.Hostname, pconfig.String())
+
+	return err
 }
 
 func (proxy *Proxy) startPort(name string, l net.Listener) {
@@ -194,6 +522,21 @@ func (proxy *Proxy) startPort(name string, l net.Listener) {
 	}
 }
 
+func (proxy *Proxy) startPacketPort(name string, pcs []net.PacketConn) {
+	proxy.mtx.RLock()
+	defer proxy.mtx.RUnlock()
+
+	// make sure port exists
+	if p, ok := proxy.ports[name]; ok {
+		go func() {
+			if err := p.startWithPacketConns(pcs); err != nil {
+				proxy.log.Error().Err(err).Msg("error starting port")
+				proxy.setStatus(model.ProxyStatusError)
+			}
+		}()
+	}
+}
+
 // close method is a method that closes all listeners ans httpServer.
 func (proxy *Proxy) close() {
 	var errs error
@@ -224,6 +567,8 @@ func (proxy *Proxy) setStatus(status model.ProxyStatus) {
 	proxy.status = status
 	proxy.mtx.Unlock()
 
+	metrics.SetProxyStatus(proxy.Config.Hostname, status)
+
 	if proxy.onUpdate != nil {
 		proxy.onUpdate(model.ProxyEvent{
 			ID:     proxy.Config.Hostname,
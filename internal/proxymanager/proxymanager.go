@@ -6,11 +6,14 @@ package proxymanager
 import (
 	"context"
 	"errors"
+	"fmt"
 	"sync"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/rs/zerolog"
 
 	"github.com/sudosu404/tailnet-lib/internal/config"
+	"github.com/sudosu404/tailnet-lib/internal/metrics"
 	"github.com/sudosu404/tailnet-lib/internal/model"
 	"github.com/sudosu404/tailnet-lib/internal/proxyproviders"
 	"github.com/sudosu404/tailnet-lib/internal/proxyproviders/tailscale"
@@ -42,6 +45,10 @@ type (
 var (
 	ErrProxyProviderNotFound  = errors.New("proxyProvider not found")
 	ErrTargetProviderNotFound = errors.New("targetProvider not found")
+	ErrProxyNotFound          = errors.New("proxy not found")
+	ErrCanaryNotFound         = errors.New("port with canary not found")
+	ErrPortNotFound           = errors.New("port not found")
+	ErrPortRunning            = errors.New("port already running")
 )
 
 // NewProxyManager function creates a new ProxyManager.
@@ -54,6 +61,14 @@ func NewProxyManager(logger zerolog.Logger) *ProxyManager {
 		log:               logger.With().Str("module", "proxymanager").Logger(),
 	}
 
+	for _, c := range []prometheus.Collector{newLimitCollector(pm), newConnCollector(pm)} {
+		// the collectors of a previous ProxyManager are replaced
+		metrics.Registry.Unregister(c)
+		if err := metrics.Registry.Register(c); err != nil {
+			pm.log.Error().Err(err).Msg("Error registering metrics collector")
+		}
+	}
+
 	return pm
 }
 
@@ -120,6 +135,8 @@ func (pm *ProxyManager) WatchEvents() {
 
 // HandleProxyEvent method handles events from a targetprovider
 func (pm *ProxyManager) HandleProxyEvent(event targetproviders.TargetEvent) {
+	metrics.IncTargetProviderEvent(pm.getTargetProviderName(event.TargetProvider), event.Action.String())
+
 	switch event.Action {
 	case targetproviders.ActionStartProxy:
 		pm.eventStart(event)
@@ -128,6 +145,8 @@ func (pm *ProxyManager) HandleProxyEvent(event targetproviders.TargetEvent) {
 	case targetproviders.ActionRestartProxy:
 		pm.eventStop(event)
 		pm.eventStart(event)
+	case targetproviders.ActionStartProt, targetproviders.ActionStopPrort, targetproviders.ActionRestartPort:
+		pm.eventPort(event)
 	}
 }
 
@@ -158,6 +177,42 @@ func (pm *ProxyManager) GetProxies() ProxyList {
 	return pm.Proxies
 }
 
+// SetMaintenance method changes the maintenance mode of a proxy at runtime.
+// Reloading the proxy configuration restores the configured maintenance mode.
+func (pm *ProxyManager) SetMaintenance(name string, m model.Maintenance) error {
+	proxy, ok := pm.GetProxy(name)
+	if !ok {
+		return ErrProxyNotFound
+	}
+
+	proxy.SetMaintenance(m)
+
+	pm.log.Info().Str("proxy", name).Bool("enabled", m.Enabled).Msg("maintenance mode changed")
+
+	return nil
+}
+
+// SetCanaryWeight method changes the canary weight of a proxy port at runtime.
+// Reloading the proxy configuration restores the configured weight.
+func (pm *ProxyManager) SetCanaryWeight(name, port string, weight float64) error {
+	if !model.IsValidPercent(weight) {
+		return fmt.Errorf("%w: %v", model.ErrInvalidPercent, weight)
+	}
+
+	proxy, ok := pm.GetProxy(name)
+	if !ok {
+		return ErrProxyNotFound
+	}
+
+	if err := proxy.SetCanaryWeight(port, weight); err != nil {
+		return err
+	}
+
+	pm.log.Info().Str("proxy", name).Str("port", port).Float64("weight", weight).Msg("canary weight changed")
+
+	return nil
+}
+
 func (pm *ProxyManager) GetProxy(name string) (*Proxy, bool) {
 	pm.mtx.RLock()
 	defer pm.mtx.RUnlock()
@@ -223,6 +278,20 @@ func (pm *ProxyManager) addTargetProvider(provider targetproviders.TargetProvide
 	pm.TargetProviders[name] = provider
 }
 
+// getTargetProviderName method returns the configured name of a TargetProvider.
+func (pm *ProxyManager) getTargetProviderName(provider targetproviders.TargetProvider) string {
+	pm.mtx.RLock()
+	defer pm.mtx.RUnlock()
+
+	for name, p := range pm.TargetProviders {
+		if p == provider {
+			return name
+		}
+	}
+
+	return ""
+}
+
 // addProxyProvider method adds	a ProxyProvider to the ProxyManager.
 func (pm *ProxyManager) addProxyProvider(provider proxyproviders.Provider, name string) {
 	pm.mtx.Lock()
@@ -252,6 +321,7 @@ func (pm *ProxyManager) removeProxy(hostname string) {
 	defer pm.mtx.Unlock()
 
 	delete(pm.Proxies, hostname)
+	metrics.DeleteProxy(hostname)
 
 	pm.log.Debug().Str("proxy", hostname).Msg("Removed proxy")
 }
@@ -288,6 +358,61 @@ func (pm *ProxyManager) eventStop(event targetproviders.TargetEvent) {
 	pm.removeProxy(proxy.Config.Hostname)
 }
 
+// eventPort method starts, stops or restarts a port of a Proxy from a event trigger.
+// The node of the proxy keeps running, a proxy that is still starting gets the new
+// port configuration before listening, a stopped or failed proxy is restarted.
+func (pm *ProxyManager) eventPort(event targetproviders.TargetEvent) {
+	log := pm.log.With().Str("targetID", event.ID).Str("port", event.Port).Str("action", event.Action.String()).Logger()
+	log.Debug().Msg("Changing target port")
+
+	proxy := pm.getProxyByTargetID(event.ID)
+	if proxy == nil {
+		pm.eventStart(event)
+		return
+	}
+
+	if !proxy.IsStarted() {
+		switch proxy.GetStatus() {
+		case model.ProxyStatusStopping, model.ProxyStatusStopped, model.ProxyStatusError:
+			log.Debug().Msg("Proxy not running, restarting target")
+			pm.eventStop(event)
+			pm.eventStart(event)
+			return
+		}
+	}
+
+	pcfg, err := event.TargetProvider.AddTarget(event.ID)
+	if err != nil {
+		log.Error().Err(err).Msg("Error updating target")
+		return
+	}
+
+	if proxy.UpdatePendingPort(event.Port, pcfg) {
+		log.Debug().Msg("Proxy starting, port updated before listening")
+		return
+	}
+
+	switch event.Action {
+	case targetproviders.ActionStartProt:
+		proxy.UpdateConfig(pcfg)
+		err = proxy.StartPort(event.Port)
+	case targetproviders.ActionStopPrort:
+		err = proxy.StopPort(event.Port)
+		proxy.UpdateConfig(pcfg)
+	case targetproviders.ActionRestartPort:
+		// the port is stopped with its previous configuration
+		if err = proxy.StopPort(event.Port); errors.Is(err, ErrPortNotFound) {
+			err = nil
+		}
+		proxy.UpdateConfig(pcfg)
+		err = errors.Join(err, proxy.StartPort(event.Port))
+	}
+
+	if err != nil {
+		log.Error().Err(err).Msg("Error changing target port")
+	}
+}
+
 // getProxyByTargetID method returns a Proxy by TargetID.
 func (pm *ProxyManager) getProxyByTargetID(targetID string) *Proxy {
 	pm.mtx.RLock()
@@ -0,0 +1,19 @@
+// SPDX-FileCopyrightText: 2025 Hector @sudosu404 <hector@email.gnx>
+// SPDX-License-Identifier: AGPL3
+
+package proxymanager
+
+import (
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+func TestNewProxyManagerTwice(t *testing.T) {
+	NewProxyManager(zerolog.Nop())
+	pm := NewProxyManager(zerolog.Nop())
+
+	if pm == nil {
+		t.Fatal("no ProxyManager")
+	}
+}
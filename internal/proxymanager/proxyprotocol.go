@@ -0,0 +1,185 @@
+// SPDX-FileCopyrightText: 2025 Hector @sudosu404 <hector@email.gnx>
+// SPDX-License-Identifier: AGPL3
+
+package proxymanager
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+
+	"github.com/sudosu404/tailnet-lib/internal/model"
+)
+
+type (
+	// proxyHeader struct stores the client connection sent in a PROXY protocol header.
+	// Invalid addresses are sent as an unknown connection.
+	proxyHeader struct {
+		src       netip.AddrPort
+		dst       netip.AddrPort
+		authority string
+		who       model.Whois
+	}
+
+	dialFunc func(ctx context.Context, network, addr string) (net.Conn, error)
+)
+
+const (
+	contextKeyProxyHeader contextKey = "contextkey.proxyheader"
+
+	proxyV2Command      = 0x21 // version 2, PROXY
+	proxyV2LocalCommand = 0x20 // version 2, LOCAL
+	proxyV2TCP4         = 0x11
+	proxyV2TCP6         = 0x21
+	proxyV2Unspec       = 0x00
+
+	// proxyV2TLVAuthority is the host name requested by the client (SNI or Host header).
+	proxyV2TLVAuthority = 0x02
+	// proxyV2TLVLogin and proxyV2TLVNode are in the range reserved for applications,
+	// they carry the Tailscale login name and node name of the client.
+	proxyV2TLVLogin = 0xE0
+	proxyV2TLVNode  = 0xE1
+)
+
+var proxyV2Signature = []byte{0x0D, 0x0A, 0x0D, 0x0A, 0x00, 0x0D, 0x0A, 0x51, 0x55, 0x49, 0x54, 0x0A}
+
+// newProxyHeader function returns the proxyHeader of a client connection.
+func newProxyHeader(remoteAddr, localAddr, authority string) proxyHeader {
+	src, _ := netip.ParseAddrPort(remoteAddr)
+	dst, _ := netip.ParseAddrPort(localAddr)
+
+	return proxyHeader{
+		src:       netip.AddrPortFrom(src.Addr().Unmap(), src.Port()),
+		dst:       netip.AddrPortFrom(dst.Addr().Unmap(), dst.Port()),
+		authority: authority,
+	}
+}
+
+// proxyHeaderMiddleware function stores the client connection of the request in the context,
+// it's sent by the proxyProtocolDialer on the target connection.
+func proxyHeaderMiddleware(version string, next http.Handler) http.Handler {
+	if version == "" {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var localAddr string
+		if addr, ok := r.Context().Value(http.LocalAddrContextKey).(net.Addr); ok {
+			localAddr = addr.String()
+		}
+
+		host := r.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+
+		h := newProxyHeader(r.RemoteAddr, localAddr, host)
+
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), contextKeyProxyHeader, h)))
+	})
+}
+
+// upstreamDialer function returns the dial function of the port targets.
+func upstreamDialer(pconfig model.PortConfig) dialFunc {
+	dial := (&net.Dialer{Timeout: pconfig.Timeouts.Dial}).DialContext
+	if pconfig.SendProxyProtocol == "" {
+		return dial
+	}
+	return proxyProtocolDialer(pconfig.SendProxyProtocol, dial)
+}
+
+// proxyProtocolDialer function returns a dial function that sends a PROXY protocol header
+// on each new connection. Connections without a client in the context, like health checks,
+// are sent as LOCAL (v2) or UNKNOWN (v1).
+func proxyProtocolDialer(version string, dial dialFunc) dialFunc {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		conn, err := dial(ctx, network, addr)
+		if err != nil {
+			return nil, err
+		}
+
+		h, _ := ctx.Value(contextKeyProxyHeader).(proxyHeader)
+		h.who, _ = model.WhoisFromContext(ctx)
+
+		if _, err := conn.Write(h.marshal(version)); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("error sending PROXY protocol header: %w", err)
+		}
+
+		return conn, nil
+	}
+}
+
+// marshal method returns the header in the PROXY protocol version.
+func (h proxyHeader) marshal(version string) []byte {
+	if version == model.ProxyProtocolV2 {
+		return h.marshalV2()
+	}
+	return h.marshalV1()
+}
+
+func (h proxyHeader) isValid() bool {
+	return h.src.IsValid() && h.dst.IsValid() && h.src.Addr().Is4() == h.dst.Addr().Is4()
+}
+
+func (h proxyHeader) marshalV1() []byte {
+	if !h.isValid() {
+		return []byte("PROXY UNKNOWN\r\n")
+	}
+
+	family := "TCP6"
+	if h.src.Addr().Is4() {
+		family = "TCP4"
+	}
+
+	return fmt.Appendf(nil, "PROXY %s %s %s %d %d\r\n",
+		family, h.src.Addr(), h.dst.Addr(), h.src.Port(), h.dst.Port())
+}
+
+func (h proxyHeader) marshalV2() []byte {
+	var b bytes.Buffer
+	b.Write(proxyV2Signature)
+
+	if !h.isValid() {
+		b.Write([]byte{proxyV2LocalCommand, proxyV2Unspec, 0, 0})
+		return b.Bytes()
+	}
+
+	var payload bytes.Buffer
+
+	family := byte(proxyV2TCP6)
+	if h.src.Addr().Is4() {
+		family = proxyV2TCP4
+	}
+
+	src, dst := h.src.Addr().AsSlice(), h.dst.Addr().AsSlice()
+	payload.Write(src)
+	payload.Write(dst)
+	_ = binary.Write(&payload, binary.BigEndian, h.src.Port())
+	_ = binary.Write(&payload, binary.BigEndian, h.dst.Port())
+
+	writeTLV(&payload, proxyV2TLVAuthority, h.authority)
+	writeTLV(&payload, proxyV2TLVLogin, h.who.Username)
+	writeTLV(&payload, proxyV2TLVNode, h.who.GetNodeName())
+
+	b.Write([]byte{proxyV2Command, family})
+	_ = binary.Write(&b, binary.BigEndian, uint16(payload.Len())) //nolint:gosec
+	b.Write(payload.Bytes())
+
+	return b.Bytes()
+}
+
+// writeTLV function writes a PROXY protocol v2 TLV, empty values are skipped.
+func writeTLV(b *bytes.Buffer, typ byte, value string) {
+	if value == "" {
+		return
+	}
+
+	b.WriteByte(typ)
+	_ = binary.Write(b, binary.BigEndian, uint16(len(value))) //nolint:gosec
+	b.WriteString(value)
+}
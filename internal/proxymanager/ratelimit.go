@@ -0,0 +1,263 @@
+// SPDX-FileCopyrightText: 2025 Hector @sudosu404 <hector@email.gnx>
+// SPDX-License-Identifier: AGPL3
+
+package proxymanager
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sudosu404/tailnet-lib/internal/model"
+
+	"github.com/rs/zerolog"
+	"golang.org/x/time/rate"
+)
+
+type (
+	// rateLimiter struct applies the rate and concurrency limits of a port.
+	rateLimiter struct {
+		log    zerolog.Logger
+		scopes []*limitScope
+	}
+
+	// limitScope struct stores the buckets of a limit scope, one per key.
+	limitScope struct {
+		name      string
+		key       func(r *http.Request) string
+		config    model.RateLimit
+		buckets   map[string]*limitBucket
+		stats     LimitStats
+		lastSweep time.Time
+		mtx       sync.Mutex
+	}
+
+	limitBucket struct {
+		limiter  *rate.Limiter
+		active   int
+		lastSeen time.Time
+	}
+
+	// LimitStats struct stores the counters of a limit scope.
+	LimitStats struct {
+		Allowed            atomic.Uint64
+		RateLimited        atomic.Uint64
+		ConcurrencyLimited atomic.Uint64
+	}
+
+	// acquired struct stores what must be released when the request ends.
+	acquired struct {
+		scope       *limitScope
+		bucket      *limitBucket
+		reservation *rate.Reservation
+		// at is the reservation time, the tokens are only restored when
+		// the reservation is canceled at the same time.
+		at time.Time
+	}
+)
+
+const (
+	LimitScopeUser     = "user"
+	LimitScopeNode     = "node"
+	LimitScopeClientIP = "clientip"
+	LimitScopeGlobal   = "global"
+
+	// buckets not used during this interval are removed
+	limitBucketTTL = 10 * time.Minute
+
+	rateLimitedMessage = "Too many requests, please retry later."
+)
+
+// newRateLimiter function returns a rateLimiter or nil if the port has no limits.
+func newRateLimiter(limits model.RateLimits, log zerolog.Logger) *rateLimiter {
+	if !limits.IsEnabled() {
+		return nil
+	}
+
+	l := &rateLimiter{
+		log: log.With().Str("module", "ratelimit").Logger(),
+	}
+
+	l.addScope(LimitScopeGlobal, limits.Global, func(*http.Request) string { return LimitScopeGlobal })
+	l.addScope(LimitScopeUser, limits.PerUser, func(r *http.Request) string {
+		who, _ := model.WhoisFromContext(r.Context())
+		return userLimitKey(who)
+	})
+	l.addScope(LimitScopeNode, limits.PerNode, func(r *http.Request) string {
+		who, _ := model.WhoisFromContext(r.Context())
+		return who.NodeName
+	})
+	l.addScope(LimitScopeClientIP, limits.PerClientIP, func(r *http.Request) string {
+		host, _, err := net.SplitHostPort(r.RemoteAddr)
+		if err != nil {
+			return r.RemoteAddr
+		}
+		return host
+	})
+
+	return l
+}
+
+// userLimitKey function returns the key of the per user limit, tagged devices
+// share the same user and are limited by node.
+func userLimitKey(who model.Whois) string {
+	if who.IsTagged() {
+		return "node:" + who.NodeID
+	}
+
+	return who.Username
+}
+
+func (l *rateLimiter) addScope(name string, config model.RateLimit, key func(r *http.Request) string) {
+	if !config.IsEnabled() {
+		return
+	}
+
+	l.scopes = append(l.scopes, &limitScope{
+		name:    name,
+		key:     key,
+		config:  config,
+		buckets: make(map[string]*limitBucket),
+	})
+}
+
+// middleware method rejects the requests over the limits with 429.
+// The Whois of the request must be resolved before.
+func (l *rateLimiter) middleware(next http.Handler) http.Handler {
+	if l == nil {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var held []acquired
+		defer func() {
+			for _, a := range held {
+				a.scope.release(a.bucket)
+			}
+		}()
+
+		for _, scope := range l.scopes {
+			// requests without identity are not limited by user or node
+			key := scope.key(r)
+			if key == "" {
+				continue
+			}
+
+			a, retryAfter, ok := scope.acquire(key)
+			if !ok {
+				for _, h := range held {
+					if h.reservation != nil {
+						h.reservation.CancelAt(h.at)
+					}
+				}
+
+				l.log.Warn().
+					Str("scope", scope.name).
+					Str("key", key).
+					Str("remoteAddr", r.RemoteAddr).
+					Msg("request limited")
+
+				w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+				renderErrorPage(w, r, http.StatusTooManyRequests, rateLimitedMessage)
+
+				return
+			}
+
+			held = append(held, a)
+		}
+
+		// the request is allowed once it passed all the scopes
+		for _, a := range held {
+			a.scope.stats.Allowed.Add(1)
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// stats method returns the counters of each limit scope.
+func (l *rateLimiter) stats() map[string]*LimitStats {
+	if l == nil {
+		return nil
+	}
+
+	stats := make(map[string]*LimitStats, len(l.scopes))
+	for _, scope := range l.scopes {
+		stats[scope.name] = &scope.stats
+	}
+
+	return stats
+}
+
+// acquire method takes a token and a concurrency slot of the key bucket.
+// Returns the seconds to wait before retrying when the request is limited.
+func (s *limitScope) acquire(key string) (acquired, int, bool) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	now := time.Now()
+	s.sweep(now)
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &limitBucket{}
+		if s.config.Requests > 0 {
+			b.limiter = rate.NewLimiter(rate.Limit(s.config.GetRate()), s.config.GetBurst())
+		}
+		s.buckets[key] = b
+	}
+	b.lastSeen = now
+
+	if s.config.MaxConcurrent > 0 && b.active >= s.config.MaxConcurrent {
+		s.stats.ConcurrencyLimited.Add(1)
+		return acquired{}, 1, false
+	}
+
+	a := acquired{scope: s, bucket: b, at: now}
+
+	if b.limiter != nil {
+		a.reservation = b.limiter.ReserveN(now, 1)
+		if delay := a.reservation.DelayFrom(now); !a.reservation.OK() || delay > 0 {
+			a.reservation.CancelAt(now)
+			s.stats.RateLimited.Add(1)
+			return acquired{}, retryAfterSeconds(delay), false
+		}
+	}
+
+	b.active++
+
+	return a, 0, true
+}
+
+func (s *limitScope) release(b *limitBucket) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	b.active--
+	b.lastSeen = time.Now()
+}
+
+// sweep method removes the idle buckets, called with the lock held.
+func (s *limitScope) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < limitBucketTTL {
+		return
+	}
+	s.lastSweep = now
+
+	for key, b := range s.buckets {
+		if b.active == 0 && now.Sub(b.lastSeen) > limitBucketTTL {
+			delete(s.buckets, key)
+		}
+	}
+}
+
+func retryAfterSeconds(d time.Duration) int {
+	if d <= 0 || d == rate.InfDuration {
+		return 1
+	}
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,117 @@
+// SPDX-FileCopyrightText: 2025 Hector @sudosu404 <hector@email.gnx>
+// SPDX-License-Identifier: AGPL3
+
+package proxymanager
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/sudosu404/tailnet-lib/internal/model"
+
+	"github.com/rs/zerolog"
+)
+
+func TestUserLimitKey(t *testing.T) {
+	tests := []struct {
+		name string
+		who  model.Whois
+		want string
+	}{
+		{
+			name: "user",
+			who:  model.Whois{Username: "alice@example.com", NodeID: "n1"},
+			want: "alice@example.com",
+		},
+		{
+			name: "tagged device",
+			who:  model.Whois{Username: "tagged-devices", NodeID: "n1", Tags: []string{"tag:server"}},
+			want: "node:n1",
+		},
+		{
+			name: "anonymous",
+			who:  model.Whois{},
+			want: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := userLimitKey(tt.who); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRateLimiterTaggedDevices(t *testing.T) {
+	limits := model.RateLimits{PerUser: model.RateLimit{Requests: 1, Period: time.Hour}}
+	l := newRateLimiter(limits, zerolog.Nop())
+	handler := l.middleware(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+
+	// each tagged device has its own bucket
+	requests := []struct {
+		node string
+		want int
+	}{
+		{node: "n1", want: http.StatusOK},
+		{node: "n2", want: http.StatusOK},
+		{node: "n1", want: http.StatusTooManyRequests},
+	}
+
+	for _, req := range requests {
+		who := model.Whois{Username: "tagged-devices", NodeID: req.node, Tags: []string{"tag:server"}}
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r = r.WithContext(model.WhoisNewContext(r.Context(), who))
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, r)
+
+		if rec.Code != req.want {
+			t.Errorf("node %s: got %d, want %d", req.node, rec.Code, req.want)
+		}
+	}
+}
+
+func TestRateLimiterStats(t *testing.T) {
+	limits := model.RateLimits{
+		Global:      model.RateLimit{Requests: 10, Period: time.Hour},
+		PerClientIP: model.RateLimit{Requests: 1, Period: time.Hour},
+	}
+	l := newRateLimiter(limits, zerolog.Nop())
+	handler := l.middleware(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+
+	codes := []int{}
+	for range 3 {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.RemoteAddr = "100.64.0.1:1234"
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, r)
+		codes = append(codes, rec.Code)
+	}
+
+	if codes[0] != http.StatusOK || codes[1] != http.StatusTooManyRequests || codes[2] != http.StatusTooManyRequests {
+		t.Fatalf("unexpected status codes %v", codes)
+	}
+
+	stats := l.stats()
+
+	// requests rejected by the client ip scope are not allowed by the global scope
+	if got := stats[LimitScopeGlobal].Allowed.Load(); got != 1 {
+		t.Errorf("global allowed: got %d, want 1", got)
+	}
+	if got := stats[LimitScopeClientIP].Allowed.Load(); got != 1 {
+		t.Errorf("client ip allowed: got %d, want 1", got)
+	}
+	if got := stats[LimitScopeClientIP].RateLimited.Load(); got != 2 {
+		t.Errorf("client ip limited: got %d, want 2", got)
+	}
+
+	// the tokens taken by the global scope are returned
+	if got := l.scopes[0].buckets[LimitScopeGlobal].limiter.Tokens(); got < 8.9 {
+		t.Errorf("global tokens: got %f, want 9", got)
+	}
+}
@@ -0,0 +1,138 @@
+// SPDX-FileCopyrightText: 2025 Hector @sudosu404 <hector@email.gnx>
+// SPDX-License-Identifier: AGPL3
+
+package proxymanager
+
+import (
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/sudosu404/tailnet-lib/internal/model"
+
+	"github.com/rs/zerolog"
+)
+
+type (
+	// redirectHandler struct answers the requests of a redirect port.
+	redirectHandler struct {
+		target *url.URL
+		rules  []*redirectRule
+		config model.Redirect
+	}
+
+	redirectRule struct {
+		regex  *regexp.Regexp
+		target string
+		status int
+	}
+)
+
+// newRedirectHandler function returns the handler of a redirect port.
+// Invalid rules are logged and ignored.
+func newRedirectHandler(pconfig model.PortConfig, log zerolog.Logger) *redirectHandler {
+	h := &redirectHandler{
+		config: pconfig.Redirect,
+	}
+
+	if len(pconfig.GetTargets()) > 0 {
+		h.target = pconfig.GetFirstTarget()
+	}
+
+	for _, cfg := range pconfig.Redirect.Rules {
+		regex, err := regexp.Compile(cfg.Match)
+		if err != nil {
+			log.Error().Err(err).Str("match", cfg.Match).Msg("invalid redirect rule regex")
+			continue
+		}
+
+		h.rules = append(h.rules, &redirectRule{
+			regex:  regex,
+			target: cfg.Target,
+			status: cfg.GetStatus(pconfig.Redirect.GetStatus()),
+		})
+	}
+
+	if h.target == nil && !h.config.HTTPS && len(h.rules) == 0 {
+		log.Error().Msg("no redirect target found for port")
+	}
+
+	return h
+}
+
+func (h *redirectHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	location, status, ok := h.location(r)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	http.Redirect(w, r, location, status)
+}
+
+// location method returns the location of a request: the first matching rule,
+// https on the same hostname or the port target.
+func (h *redirectHandler) location(r *http.Request) (string, int, bool) {
+	for _, rule := range h.rules {
+		if location, ok := rule.location(r.URL); ok {
+			return location, rule.status, true
+		}
+	}
+
+	switch {
+	case h.config.HTTPS:
+		host := r.Host
+		if hostname, _, err := net.SplitHostPort(host); err == nil {
+			host = hostname
+		}
+		return "https://" + host + r.URL.RequestURI(), h.config.GetStatus(), true
+
+	case h.target != nil:
+		if !h.config.PreservePath {
+			return h.target.String(), h.config.GetStatus(), true
+		}
+		return joinRedirectURL(h.target, r.URL).String(), h.config.GetStatus(), true
+	}
+
+	return "", 0, false
+}
+
+// location method returns the expanded target of the rule if it matches the request path.
+// The request query is kept if the target has no query.
+func (rr *redirectRule) location(u *url.URL) (string, bool) {
+	reqPath := u.EscapedPath()
+
+	match := rr.regex.FindStringSubmatchIndex(reqPath)
+	if match == nil {
+		return "", false
+	}
+
+	location := string(rr.regex.ExpandString(nil, rr.target, reqPath, match))
+	if u.RawQuery != "" && !strings.Contains(location, "?") {
+		location += "?" + u.RawQuery
+	}
+
+	return location, true
+}
+
+// joinRedirectURL function appends the path and the query of a request to a target.
+func joinRedirectURL(target, req *url.URL) *url.URL {
+	u := *target
+
+	u.Path = strings.TrimSuffix(target.Path, "/") + req.Path
+	u.RawPath = ""
+	if req.RawPath != "" || target.RawPath != "" {
+		u.RawPath = strings.TrimSuffix(target.EscapedPath(), "/") + req.EscapedPath()
+	}
+
+	switch {
+	case target.RawQuery == "":
+		u.RawQuery = req.RawQuery
+	case req.RawQuery != "":
+		u.RawQuery = target.RawQuery + "&" + req.RawQuery
+	}
+
+	return &u
+}
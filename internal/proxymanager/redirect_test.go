@@ -0,0 +1,78 @@
+// SPDX-FileCopyrightText: 2025 Hector @sudosu404 <hector@email.gnx>
+// SPDX-License-Identifier: AGPL3
+
+package proxymanager
+
+import (
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/sudosu404/tailnet-lib/internal/model"
+
+	"github.com/rs/zerolog"
+)
+
+func TestRedirectLocation(t *testing.T) {
+	pconfig := model.PortConfig{
+		Redirect: model.Redirect{
+			Status:       308,
+			PreservePath: true,
+			Rules: []model.RedirectRule{
+				{Match: "^/old/(.*)$", Target: "/new/$1"},
+				{Match: "^/docs/(?P<page>[^/]+)$", Target: "https://docs.example.com/${page}?from=tailnet", Status: 302},
+				{Match: "([", Target: "/invalid"},
+			},
+		},
+	}
+	target, _ := url.Parse("https://example.com/base/")
+	pconfig.AddTarget(target)
+
+	h := newRedirectHandler(pconfig, zerolog.Nop())
+	if len(h.rules) != 2 {
+		t.Fatalf("got %d rules, want 2", len(h.rules))
+	}
+
+	tests := []struct {
+		url          string
+		wantLocation string
+		wantStatus   int
+	}{
+		{url: "http://app/old/a/b?x=1", wantLocation: "/new/a/b?x=1", wantStatus: 308},
+		{url: "http://app/docs/intro?x=1", wantLocation: "https://docs.example.com/intro?from=tailnet", wantStatus: 302},
+		{url: "http://app/other?x=1", wantLocation: "https://example.com/base/other?x=1", wantStatus: 308},
+		{url: "http://app/a%2Fb", wantLocation: "https://example.com/base/a%2Fb", wantStatus: 308},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			location, status, ok := h.location(httptest.NewRequest("GET", tt.url, nil))
+			if !ok {
+				t.Fatal("no location")
+			}
+			if location != tt.wantLocation || status != tt.wantStatus {
+				t.Errorf("got %s %d, want %s %d", location, status, tt.wantLocation, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestRedirectHTTPS(t *testing.T) {
+	h := newRedirectHandler(model.PortConfig{Redirect: model.Redirect{HTTPS: true}}, zerolog.Nop())
+
+	location, status, ok := h.location(httptest.NewRequest("GET", "http://app.example.com:80/a?b=1", nil))
+	if !ok {
+		t.Fatal("no location")
+	}
+	if location != "https://app.example.com/a?b=1" || status != model.DefaultRedirectStatus {
+		t.Errorf("got %s %d", location, status)
+	}
+}
+
+func TestRedirectWithoutTarget(t *testing.T) {
+	h := newRedirectHandler(model.PortConfig{}, zerolog.Nop())
+
+	if _, _, ok := h.location(httptest.NewRequest("GET", "http://app/a", nil)); ok {
+		t.Error("location without target")
+	}
+}
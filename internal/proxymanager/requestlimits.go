@@ -0,0 +1,100 @@
+// SPDX-FileCopyrightText: 2025 Hector @sudosu404 <hector@email.gnx>
+// SPDX-License-Identifier: AGPL3
+
+package proxymanager
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/sudosu404/tailnet-lib/internal/model"
+)
+
+type (
+	// limitedBody struct records the error of reading the request body,
+	// so the error handler can tell client errors from target errors.
+	limitedBody struct {
+		io.ReadCloser
+		err error
+	}
+)
+
+const (
+	contextKeyLimitedBody contextKey = "contextkey.limitedbody"
+)
+
+// requestLimitsMiddleware function applies the body size limit and the read and
+// request timeouts of a port.
+func requestLimitsMiddleware(pconfig model.PortConfig, next http.Handler) http.Handler {
+	maxBody := int64(pconfig.MaxBodySize)
+	timeouts := pconfig.Timeouts
+
+	if maxBody <= 0 && timeouts.Read <= 0 && timeouts.Request <= 0 {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if maxBody > 0 && r.ContentLength > maxBody {
+			renderErrorPage(w, r, http.StatusRequestEntityTooLarge, "")
+			return
+		}
+
+		ctx := r.Context()
+
+		if r.Body != nil && r.Body != http.NoBody {
+			body := &limitedBody{ReadCloser: r.Body}
+			if maxBody > 0 {
+				body.ReadCloser = http.MaxBytesReader(w, r.Body, maxBody)
+			}
+
+			r.Body = body
+			ctx = context.WithValue(ctx, contextKeyLimitedBody, body)
+		}
+
+		if timeouts.Read > 0 {
+			// not supported by all the connections, the request timeout still applies
+			_ = http.NewResponseController(w).SetReadDeadline(time.Now().Add(timeouts.Read))
+		}
+
+		if timeouts.Request > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeouts.Request)
+			defer cancel()
+		}
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if err != nil && !errors.Is(err, io.EOF) {
+		b.err = err
+	}
+	return n, err
+}
+
+// requestBodyStatus function returns the status code of a failed request body read,
+// 413 if the body is too large and 408 if the read timed out.
+func requestBodyStatus(r *http.Request) (int, bool) {
+	body, ok := r.Context().Value(contextKeyLimitedBody).(*limitedBody)
+	if !ok || body.err == nil {
+		return 0, false
+	}
+
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(body.err, &maxBytesErr) {
+		return http.StatusRequestEntityTooLarge, true
+	}
+
+	var netErr net.Error
+	if errors.As(body.err, &netErr) && netErr.Timeout() {
+		return http.StatusRequestTimeout, true
+	}
+
+	return 0, false
+}
@@ -0,0 +1,193 @@
+// SPDX-FileCopyrightText: 2025 Hector @sudosu404 <hector@email.gnx>
+// SPDX-License-Identifier: AGPL3
+
+package proxymanager
+
+import (
+	"net"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/sudosu404/tailnet-lib/internal/model"
+
+	"github.com/rs/zerolog"
+)
+
+type (
+	// router struct selects the balancer of a request from the port routes.
+	router struct {
+		routes   []*route
+		fallback *balancer
+	}
+
+	route struct {
+		config   model.Route
+		regex    *regexp.Regexp
+		balancer *balancer
+	}
+)
+
+const headerForwardedPrefix = "X-Forwarded-Prefix"
+
+// newRouter function returns a router for the routes of a port.
+// Invalid routes are logged and ignored.
+func newRouter(pconfig model.PortConfig, fallback *balancer, log zerolog.Logger) *router {
+	rt := &router{
+		fallback: fallback,
+	}
+
+	for i, cfg := range pconfig.Routes {
+		newRoute := &route{config: cfg}
+
+		if cfg.PathRegex != "" {
+			regex, err := regexp.Compile(cfg.PathRegex)
+			if err != nil {
+				log.Error().Err(err).Str("route", cfg.Name).Msg("invalid route path regex")
+				continue
+			}
+			newRoute.regex = regex
+		}
+
+		if len(cfg.GetTargets()) == 0 {
+			log.Error().Str("route", cfg.Name).Msg("no targets found for route")
+			continue
+		}
+
+		routeConfig := model.PortConfig{
+			ProxyPort: pconfig.ProxyPort,
+			LoadBalancer: model.LoadBalancer{
+				Strategy: pconfig.LoadBalancer.Strategy,
+				Affinity: pconfig.LoadBalancer.Affinity,
+			},
+		}
+		// each route has its own targets, and its own affinity cookie
+		routeConfig.LoadBalancer.Affinity.Cookie = pconfig.LoadBalancer.Affinity.GetCookie(pconfig.ProxyPort) + "_" + strconv.Itoa(i)
+		for _, target := range cfg.GetTargets() {
+			routeConfig.AddTarget(target)
+		}
+		newRoute.balancer = newBalancer(routeConfig)
+
+		rt.routes = append(rt.routes, newRoute)
+	}
+
+	return rt
+}
+
+// upstreams method returns the upstreams of the port and all routes.
+func (rt *router) upstreams() []*upstream {
+	upstreams := append([]*upstream{}, rt.fallback.upstreams...)
+	for _, r := range rt.routes {
+		upstreams = append(upstreams, r.balancer.upstreams...)
+	}
+
+	return upstreams
+}
+
+// middleware method forwards the request to the balancer of the first matching route.
+// The requests without a matching route use the port targets, or the canary
+// when the request is assigned to it: the routes keep their own targets.
+func (rt *router) middleware(next http.Handler, c *canary) http.Handler {
+	fallback := c.middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rt.fallback.serve(w, r, next)
+	}), next)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for _, route := range rt.routes {
+			if route.match(r) {
+				route.balancer.serve(w, route.rewrite(r), next)
+				return
+			}
+		}
+
+		if len(rt.fallback.upstreams) == 0 {
+			http.NotFound(w, r)
+			return
+		}
+
+		fallback.ServeHTTP(w, r)
+	})
+}
+
+// match method returns true if all configured matchers match the request.
+func (r *route) match(req *http.Request) bool {
+	if r.config.Host != "" && !matchHost(r.config.Host, req.Host) {
+		return false
+	}
+
+	if r.config.PathPrefix != "" && !matchPathPrefix(r.config.PathPrefix, req.URL.Path) {
+		return false
+	}
+
+	if r.regex != nil && !r.regex.MatchString(req.URL.Path) {
+		return false
+	}
+
+	if r.config.Capability != "" {
+		who, _ := model.WhoisFromContext(req.Context())
+		if !who.HasCapability(r.config.Capability) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// rewrite method returns the request with the path sent to the targets.
+func (r *route) rewrite(req *http.Request) *http.Request {
+	path := req.URL.Path
+
+	switch {
+	case r.regex != nil && r.config.Rewrite != "":
+		path = r.regex.ReplaceAllString(path, r.config.Rewrite)
+	case r.config.PathPrefix != "" && (r.config.StripPrefix || r.config.Rewrite != ""):
+		rest := strings.TrimPrefix(path, strings.TrimSuffix(r.config.PathPrefix, "/"))
+		path = strings.TrimSuffix(r.config.Rewrite, "/") + rest
+	default:
+		return req
+	}
+
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+
+	newURL := *req.URL
+	newURL.Path = path
+	newURL.RawPath = ""
+
+	newReq := req.WithContext(req.Context())
+	newReq.URL = &newURL
+
+	if r.config.StripPrefix && r.config.PathPrefix != "" {
+		newReq.Header.Set(headerForwardedPrefix, strings.TrimSuffix(r.config.PathPrefix, "/"))
+	}
+
+	return newReq
+}
+
+// matchPathPrefix function matches a prefix on path segment boundaries,
+// "/grafana" matches "/grafana" and "/grafana/x" but not "/grafanax".
+func matchPathPrefix(prefix, path string) bool {
+	if strings.HasSuffix(prefix, "/") {
+		return strings.HasPrefix(path, prefix) || path == strings.TrimSuffix(prefix, "/")
+	}
+
+	return path == prefix || strings.HasPrefix(path, prefix+"/")
+}
+
+// matchHost function matches the request host, without port, with a host pattern.
+func matchHost(pattern, host string) bool {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	pattern = strings.ToLower(pattern)
+
+	if domain, ok := strings.CutPrefix(pattern, "*."); ok {
+		return strings.HasSuffix(host, "."+domain)
+	}
+
+	return host == pattern
+}
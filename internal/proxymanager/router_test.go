@@ -0,0 +1,126 @@
+// SPDX-FileCopyrightText: 2025 Hector @sudosu404 <hector@email.gnx>
+// SPDX-License-Identifier: AGPL3
+
+package proxymanager
+
+import (
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"github.com/sudosu404/tailnet-lib/internal/model"
+)
+
+func TestMatchPathPrefix(t *testing.T) {
+	tests := []struct {
+		prefix string
+		path   string
+		want   bool
+	}{
+		{prefix: "/grafana", path: "/grafana", want: true},
+		{prefix: "/grafana", path: "/grafana/x", want: true},
+		{prefix: "/grafana", path: "/grafanax", want: false},
+		{prefix: "/grafana/", path: "/grafana", want: true},
+		{prefix: "/grafana/", path: "/grafana/x", want: true},
+		{prefix: "/", path: "/x", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.prefix+" "+tt.path, func(t *testing.T) {
+			if got := matchPathPrefix(tt.prefix, tt.path); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchHost(t *testing.T) {
+	tests := []struct {
+		pattern string
+		host    string
+		want    bool
+	}{
+		{pattern: "app.example.com", host: "app.example.com", want: true},
+		{pattern: "app.example.com", host: "APP.example.com:443", want: true},
+		{pattern: "app.example.com", host: "app.example.com.", want: true},
+		{pattern: "*.example.com", host: "a.example.com", want: true},
+		{pattern: "*.example.com", host: "example.com", want: false},
+		{pattern: "app.example.com", host: "other.example.com", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.host, func(t *testing.T) {
+			if got := matchHost(tt.pattern, tt.host); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRouteRewrite(t *testing.T) {
+	tests := []struct {
+		name       string
+		config     model.Route
+		path       string
+		wantPath   string
+		wantPrefix string
+	}{
+		{
+			name:       "strip prefix",
+			config:     model.Route{PathPrefix: "/grafana", StripPrefix: true},
+			path:       "/grafana/d/1",
+			wantPath:   "/d/1",
+			wantPrefix: "/grafana",
+		},
+		{
+			name:       "strip prefix of the prefix path",
+			config:     model.Route{PathPrefix: "/grafana/", StripPrefix: true},
+			path:       "/grafana",
+			wantPath:   "/",
+			wantPrefix: "/grafana",
+		},
+		{
+			name:     "rewrite prefix",
+			config:   model.Route{PathPrefix: "/v1", Rewrite: "/api/v1"},
+			path:     "/v1/users",
+			wantPath: "/api/v1/users",
+		},
+		{
+			name:     "rewrite regex",
+			config:   model.Route{PathRegex: "^/v[0-9]{1,3}/(.*)$", Rewrite: "/api/$1"},
+			path:     "/v12/users",
+			wantPath: "/api/users",
+		},
+		{
+			name:     "unchanged",
+			config:   model.Route{PathPrefix: "/grafana"},
+			path:     "/grafana/d/1",
+			wantPath: "/grafana/d/1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &route{config: tt.config}
+			if tt.config.PathRegex != "" {
+				r.regex = regexp.MustCompile(tt.config.PathRegex)
+			}
+
+			req := httptest.NewRequest("GET", tt.path, nil)
+			if !r.match(req) {
+				t.Fatalf("route doesn't match %s", tt.path)
+			}
+
+			got := r.rewrite(req)
+			if got.URL.Path != tt.wantPath {
+				t.Errorf("got path %q, want %q", got.URL.Path, tt.wantPath)
+			}
+			if prefix := got.Header.Get(headerForwardedPrefix); prefix != tt.wantPrefix {
+				t.Errorf("got %s %q, want %q", headerForwardedPrefix, prefix, tt.wantPrefix)
+			}
+			if req.URL.Path != tt.path {
+				t.Errorf("original request path changed to %q", req.URL.Path)
+			}
+		})
+	}
+}
@@ -0,0 +1,292 @@
+// SPDX-FileCopyrightText: 2025 Hector @sudosu404 <hector@email.gnx>
+// SPDX-License-Identifier: AGPL3
+
+package proxymanager
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sudosu404/tailnet-lib/internal/model"
+
+	"github.com/rs/zerolog"
+)
+
+type (
+	// tcpProxy struct forwards raw tcp streams to the port upstreams.
+	tcpProxy struct {
+		log         zerolog.Logger
+		balancer    *balancer
+		sni         *sniRouter
+		healthCheck *healthChecker
+		conns       map[net.Conn]struct{}
+		stats       connStats
+		idleTimeout time.Duration
+		dialTimeout time.Duration
+		// proxyProtocol is the PROXY protocol version sent to the targets, with the
+		// client identity in the v2 TLVs.
+		proxyProtocol string
+		access        *connAccess
+		closed        bool
+		mtx           sync.Mutex
+	}
+
+	// connStats struct stores the connection accounting of passthrough ports.
+	connStats struct {
+		active   atomic.Int64
+		total    atomic.Uint64
+		bytesIn  atomic.Uint64
+		bytesOut atomic.Uint64
+	}
+
+	// idleConn struct extends the connection deadline on every read and write.
+	idleConn struct {
+		net.Conn
+		timeout time.Duration
+	}
+)
+
+const defaultPassthroughDialTimeout = 10 * time.Second
+
+var ErrNoUpstreamAvailable = errors.New("no upstream available")
+
+// passthroughDialTimeout function returns the dial timeout of the port or the default one.
+func passthroughDialTimeout(pconfig model.PortConfig) time.Duration {
+	if pconfig.Timeouts.Dial > 0 {
+		return pconfig.Timeouts.Dial
+	}
+	return defaultPassthroughDialTimeout
+}
+
+// newTCPProxy function returns the proxy of a tcp or tls port, sni routes the tls
+// connections by server name and is nil for tcp ports.
+func newTCPProxy(pconfig model.PortConfig, lb *balancer, sni *sniRouter, hc *healthChecker,
+	log zerolog.Logger, access *connAccess,
+) *tcpProxy {
+	return &tcpProxy{
+		log:           log,
+		balancer:      lb,
+		sni:           sni,
+		healthCheck:   hc,
+		conns:         make(map[net.Conn]struct{}),
+		idleTimeout:   pconfig.GetIdleTimeout(),
+		dialTimeout:   passthroughDialTimeout(pconfig),
+		proxyProtocol: pconfig.SendProxyProtocol,
+		access:        access,
+	}
+}
+
+// Serve method accepts connections until the listener is closed.
+func (t *tcpProxy) Serve(l net.Listener) error {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return err
+		}
+
+		go t.handle(conn)
+	}
+}
+
+// Shutdown method closes all active connections.
+func (t *tcpProxy) Shutdown(_ context.Context) error {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	t.closed = true
+
+	var errs error
+	for conn := range t.conns {
+		errs = errors.Join(errs, conn.Close())
+	}
+
+	return errs
+}
+
+func (t *tcpProxy) handle(client net.Conn) {
+	start := time.Now()
+	log := t.log.With().Str("client", client.RemoteAddr().String()).Logger()
+
+	if !t.track(client) {
+		client.Close()
+		return
+	}
+	defer t.untrack(client)
+
+	clientAddr := client.RemoteAddr().String()
+	who := t.access.whois(clientAddr)
+	if !t.access.isAllowed(who, clientAddr) {
+		t.access.logConn(start, clientAddr, who, "", "", http.StatusForbidden, 0, 0)
+		return
+	}
+
+	lb := t.balancer
+
+	var serverName string
+	if t.sni != nil {
+		var (
+			conn net.Conn
+			err  error
+		)
+
+		serverName, conn, err = peekClientHello(client)
+		if err != nil {
+			log.Debug().Err(err).Msg("using default targets")
+		}
+
+		log = log.With().Str("serverName", serverName).Logger()
+		client = conn
+		lb = t.sni.route(serverName)
+	}
+
+	u, upstreamConn, err := dialUpstream("tcp", lb, t.healthCheck, t.dialTimeout, log)
+	if err != nil {
+		log.Error().Err(err).Msg("error forwarding connection")
+		t.access.logConn(start, clientAddr, who, serverName, "", http.StatusBadGateway, 0, 0)
+		return
+	}
+
+	u.active.Add(1)
+	defer u.active.Add(-1)
+
+	if !t.track(upstreamConn) {
+		upstreamConn.Close()
+		return
+	}
+	defer t.untrack(upstreamConn)
+
+	if t.proxyProtocol != "" {
+		if err := t.writeProxyHeader(upstreamConn, client, serverName, who); err != nil {
+			log.Error().Err(err).Str("target", u.url.String()).Msg("error sending PROXY protocol header")
+			return
+		}
+	}
+
+	bytesIn, bytesOut := pipe(t.wrap(client), t.wrap(upstreamConn))
+
+	t.stats.bytesIn.Add(uint64(bytesIn))   //nolint:gosec
+	t.stats.bytesOut.Add(uint64(bytesOut)) //nolint:gosec
+
+	t.access.logConn(start, clientAddr, who, serverName, u.url.String(), http.StatusOK, bytesIn, bytesOut)
+}
+
+// dialUpstream function connects to an upstream of lb, a failed dial is reported to
+// the health checks and the connection is retried with the next upstream.
+func dialUpstream(network string, lb *balancer, hc *healthChecker, timeout time.Duration,
+	log zerolog.Logger,
+) (*upstream, net.Conn, error) {
+	var failed []*upstream
+
+	for {
+		u := lb.nextSkipping(failed)
+		if u == nil {
+			return nil, nil, ErrNoUpstreamAvailable
+		}
+
+		conn, err := net.DialTimeout(network, u.address(), timeout)
+		if err == nil {
+			return u, conn, nil
+		}
+
+		log.Warn().Err(err).Str("target", u.url.String()).Msg("error dialing upstream")
+		hc.reportFailure(u, err)
+
+		failed = append(failed, u)
+	}
+}
+
+// writeProxyHeader method sends the PROXY protocol header of the client connection to the target.
+func (t *tcpProxy) writeProxyHeader(upstreamConn, client net.Conn, serverName string, who model.Whois) error {
+	h := newProxyHeader(client.RemoteAddr().String(), client.LocalAddr().String(), serverName)
+	h.who = who
+
+	_, err := upstreamConn.Write(h.marshal(t.proxyProtocol))
+
+	return err
+}
+
+// track method registers an active connection, returns false if the proxy is closed.
+func (t *tcpProxy) track(conn net.Conn) bool {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	if t.closed {
+		return false
+	}
+
+	t.conns[conn] = struct{}{}
+	t.stats.active.Add(1)
+	t.stats.total.Add(1)
+
+	return true
+}
+
+func (t *tcpProxy) untrack(conn net.Conn) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	if _, ok := t.conns[conn]; ok {
+		delete(t.conns, conn)
+		t.stats.active.Add(-1)
+		conn.Close()
+	}
+}
+
+func (t *tcpProxy) wrap(conn net.Conn) net.Conn {
+	if t.idleTimeout <= 0 {
+		return conn
+	}
+	return &idleConn{Conn: conn, timeout: t.idleTimeout}
+}
+
+func (c *idleConn) Read(b []byte) (int, error) {
+	_ = c.SetDeadline(time.Now().Add(c.timeout))
+	return c.Conn.Read(b)
+}
+
+func (c *idleConn) Write(b []byte) (int, error) {
+	_ = c.SetDeadline(time.Now().Add(c.timeout))
+	return c.Conn.Write(b)
+}
+
+// pipe function copies data in both directions until one side is closed.
+// Returns the bytes sent from client to upstream and from upstream to client.
+func pipe(client, upstream net.Conn) (int64, int64) {
+	var bytesIn, bytesOut int64
+
+	wg := sync.WaitGroup{}
+	wg.Add(1)
+
+	go func() {
+		defer wg.Done()
+		bytesOut, _ = io.Copy(client, upstream)
+		closeWrite(client)
+	}()
+
+	bytesIn, _ = io.Copy(upstream, client)
+	closeWrite(upstream)
+
+	wg.Wait()
+
+	return bytesIn, bytesOut
+}
+
+// closeWrite function signals the end of the stream to the other side.
+func closeWrite(conn net.Conn) {
+	if c, ok := conn.(*idleConn); ok {
+		conn = c.Conn
+	}
+
+	if c, ok := conn.(interface{ CloseWrite() error }); ok {
+		_ = c.CloseWrite()
+		return
+	}
+
+	_ = conn.Close()
+}
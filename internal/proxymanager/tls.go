@@ -0,0 +1,141 @@
+// SPDX-FileCopyrightText: 2025 Hector @sudosu404 <hector@email.gnx>
+// SPDX-License-Identifier: AGPL3
+
+package proxymanager
+
+import (
+	"bytes"
+	"crypto/tls"
+	"errors"
+	"io"
+	"maps"
+	"net"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/sudosu404/tailnet-lib/internal/model"
+)
+
+type (
+	// sniRouter struct selects the balancer of a tls passthrough connection by server name.
+	sniRouter struct {
+		routes   map[string]*balancer
+		fallback *balancer
+	}
+
+	// peekedConn struct replays the bytes read while peeking the ClientHello.
+	peekedConn struct {
+		net.Conn
+		reader io.Reader
+	}
+
+	// readOnlyConn struct is used to parse the ClientHello without writing to the client.
+	readOnlyConn struct {
+		net.Conn
+		reader io.Reader
+	}
+)
+
+const clientHelloTimeout = 5 * time.Second
+
+var ErrNoServerName = errors.New("no server name in ClientHello")
+
+// newSNIRouter function returns a router for the SNI routes of a port.
+func newSNIRouter(pconfig model.PortConfig, fallback *balancer) *sniRouter {
+	routes := make(map[string]*balancer, len(pconfig.SNIRoutes))
+
+	for name, target := range pconfig.SNIRoutes {
+		routeConfig := model.PortConfig{}
+		routeConfig.AddTarget(target)
+
+		routes[strings.ToLower(name)] = newBalancer(routeConfig)
+	}
+
+	return &sniRouter{
+		routes:   routes,
+		fallback: fallback,
+	}
+}
+
+// upstreams method returns the upstreams of the fallback targets and all SNI routes.
+func (s *sniRouter) upstreams() []*upstream {
+	upstreams := append([]*upstream{}, s.fallback.upstreams...)
+	for _, name := range slices.Sorted(maps.Keys(s.routes)) {
+		upstreams = append(upstreams, s.routes[name].upstreams...)
+	}
+
+	return upstreams
+}
+
+// route method returns the balancer for a server name.
+// Exact names have priority over wildcards like "*.example.com".
+func (s *sniRouter) route(serverName string) *balancer {
+	serverName = strings.ToLower(strings.TrimSuffix(serverName, "."))
+
+	if lb, ok := s.routes[serverName]; ok {
+		return lb
+	}
+
+	if _, domain, ok := strings.Cut(serverName, "."); ok {
+		if lb, ok := s.routes["*."+domain]; ok {
+			return lb
+		}
+	}
+
+	return s.fallback
+}
+
+// peekClientHello function reads the ClientHello of a tls connection and returns
+// the server name and a connection that replays the read bytes.
+func peekClientHello(conn net.Conn) (string, net.Conn, error) {
+	_ = conn.SetReadDeadline(time.Now().Add(clientHelloTimeout))
+	defer func() { _ = conn.SetReadDeadline(time.Time{}) }()
+
+	peeked := new(bytes.Buffer)
+
+	var hello *tls.ClientHelloInfo
+
+	// the handshake always fails because no certificate is returned
+	_ = tls.Server(readOnlyConn{Conn: conn, reader: io.TeeReader(conn, peeked)}, &tls.Config{
+		GetConfigForClient: func(info *tls.ClientHelloInfo) (*tls.Config, error) {
+			hello = info
+			return nil, ErrNoServerName
+		},
+	}).Handshake()
+
+	wrapped := &peekedConn{
+		Conn:   conn,
+		reader: io.MultiReader(peeked, conn),
+	}
+
+	if hello == nil || hello.ServerName == "" {
+		return "", wrapped, ErrNoServerName
+	}
+
+	return hello.ServerName, wrapped, nil
+}
+
+func (c *peekedConn) Read(b []byte) (int, error) {
+	return c.reader.Read(b)
+}
+
+// CloseWrite method signals the end of the stream when supported by the connection.
+func (c *peekedConn) CloseWrite() error {
+	if cw, ok := c.Conn.(interface{ CloseWrite() error }); ok {
+		return cw.CloseWrite()
+	}
+	return c.Close()
+}
+
+func (c readOnlyConn) Read(b []byte) (int, error) {
+	return c.reader.Read(b)
+}
+
+func (c readOnlyConn) Write(_ []byte) (int, error) {
+	return 0, io.ErrClosedPipe
+}
+
+func (c readOnlyConn) Close() error {
+	return nil
+}
@@ -0,0 +1,58 @@
+// SPDX-FileCopyrightText: 2025 Hector @sudosu404 <hector@email.gnx>
+// SPDX-License-Identifier: AGPL3
+
+package proxymanager
+
+import (
+	"net/http"
+
+	"github.com/sudosu404/tailnet-lib/internal/model"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracingMiddleware function starts a server span for each request of a port,
+// continuing the W3C trace context sent by the client.
+func tracingMiddleware(proxyName, portName string, next http.Handler) http.Handler {
+	return otelhttp.NewHandler(next, proxyName,
+		otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string {
+			return r.Method + " " + proxyName
+		}),
+		otelhttp.WithSpanOptions(trace.WithAttributes(
+			attribute.String("tailnet.proxy", proxyName),
+			attribute.String("tailnet.port", portName),
+		)),
+	)
+}
+
+// spanWhoisMiddleware function adds the Whois and the upstream of the request to the span.
+// The Whois of the request must be resolved before.
+func spanWhoisMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		span := trace.SpanFromContext(r.Context())
+		if !span.IsRecording() {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if who, ok := model.WhoisFromContext(r.Context()); ok {
+			span.SetAttributes(
+				attribute.String("enduser.id", who.Username),
+				attribute.String("tailnet.user.id", who.ID),
+				attribute.String("tailnet.user.login", who.Username),
+				attribute.String("tailnet.node.name", who.NodeName),
+				attribute.StringSlice("tailnet.node.tags", who.Tags),
+				attribute.String("tailnet.node.id", who.NodeID),
+				attribute.StringSlice("tailnet.capabilities", who.GetCapabilityNames()),
+			)
+		}
+
+		next.ServeHTTP(w, r)
+
+		if info, ok := requestInfoFromContext(r.Context()); ok && info.upstream != "" {
+			span.SetAttributes(attribute.String("tailnet.upstream", info.upstream))
+		}
+	})
+}
@@ -0,0 +1,118 @@
+// SPDX-FileCopyrightText: 2025 Hector @sudosu404 <hector@email.gnx>
+// SPDX-License-Identifier: AGPL3
+
+package proxymanager
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/sudosu404/tailnet-lib/internal/model"
+
+	"github.com/rs/zerolog"
+)
+
+type (
+	// upstreamTransport struct sends the requests with the transport of the target scheme.
+	// h2c and grpc targets use HTTP/2 without TLS, grpcs targets use HTTP/2 with TLS.
+	upstreamTransport struct {
+		http1 *http.Transport
+		h2c   *http.Transport
+		h2    *http.Transport
+	}
+)
+
+var ErrNoCertificates = errors.New("no certificates found")
+
+// newUpstreamTLSConfig function returns the TLS configuration of the connections to the port targets.
+// Invalid files are logged: a CA bundle that can't be loaded trusts no certificate,
+// and a client certificate that can't be loaded is not sent.
+func newUpstreamTLSConfig(pconfig model.PortConfig, log zerolog.Logger) *tls.Config {
+	config := &tls.Config{
+		InsecureSkipVerify: !pconfig.TLSValidate, //nolint:gosec
+		ServerName:         pconfig.UpstreamTLS.ServerName,
+	}
+
+	if pconfig.UpstreamTLS.CAFile != "" {
+		pool, err := loadCertPool(pconfig.UpstreamTLS.CAFile)
+		if err != nil {
+			log.Error().Err(err).Str("file", pconfig.UpstreamTLS.CAFile).Msg("error loading upstream CA bundle")
+			pool = x509.NewCertPool()
+		}
+		config.RootCAs = pool
+	}
+
+	if pconfig.UpstreamTLS.CertFile != "" || pconfig.UpstreamTLS.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(pconfig.UpstreamTLS.CertFile, pconfig.UpstreamTLS.KeyFile)
+		if err != nil {
+			log.Error().Err(err).Str("file", pconfig.UpstreamTLS.CertFile).Msg("error loading upstream client certificate")
+		} else {
+			config.Certificates = []tls.Certificate{cert}
+		}
+	}
+
+	return config
+}
+
+// loadCertPool function returns the system roots with the certificates of a PEM bundle.
+func loadCertPool(file string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("%w in %s", ErrNoCertificates, file)
+	}
+
+	return pool, nil
+}
+
+// newUpstreamTransport function returns an upstreamTransport based on tr.
+func newUpstreamTransport(tr *http.Transport) *upstreamTransport {
+	h2c := tr.Clone()
+	h2c.Protocols = new(http.Protocols)
+	h2c.Protocols.SetUnencryptedHTTP2(true)
+
+	h2 := tr.Clone()
+	h2.Protocols = new(http.Protocols)
+	h2.Protocols.SetHTTP2(true)
+
+	return &upstreamTransport{
+		http1: tr,
+		h2c:   h2c,
+		h2:    h2,
+	}
+}
+
+// RoundTrip method implements http.RoundTripper.
+func (t *upstreamTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	switch req.URL.Scheme {
+	case model.SchemeH2C, model.SchemeGRPC:
+		return t.h2c.RoundTrip(withScheme(req, "http"))
+	case model.SchemeGRPCS:
+		return t.h2.RoundTrip(withScheme(req, "https"))
+	}
+
+	return t.http1.RoundTrip(req)
+}
+
+// withScheme function returns a shallow copy of the request with the URL scheme replaced.
+func withScheme(req *http.Request, scheme string) *http.Request {
+	u := *req.URL
+	u.Scheme = scheme
+
+	out := req.WithContext(req.Context())
+	out.URL = &u
+
+	return out
+}
@@ -0,0 +1,215 @@
+// SPDX-FileCopyrightText: 2025 Hector @sudosu404 <hector@email.gnx>
+// SPDX-License-Identifier: AGPL3
+
+package proxymanager
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/sudosu404/tailnet-lib/internal/model"
+
+	"github.com/rs/zerolog"
+)
+
+type (
+	// udpProxy struct forwards udp datagrams to the port upstreams.
+	// Each client address gets its own upstream socket, used to route replies back.
+	udpProxy struct {
+		log         zerolog.Logger
+		balancer    *balancer
+		healthCheck *healthChecker
+		sessions    map[string]*udpSession
+		// denied stores until when the datagrams of the clients denied by the
+		// access policies are dropped without resolving their identity again.
+		denied      map[string]time.Time
+		stats       connStats
+		idleTimeout time.Duration
+		dialTimeout time.Duration
+		access      *connAccess
+		closed      bool
+		mtx         sync.Mutex
+	}
+
+	udpSession struct {
+		upstream *upstream
+		conn     net.Conn
+		who      model.Whois
+		start    time.Time
+		bytesIn  int64
+		bytesOut int64
+	}
+)
+
+const udpBufferSize = 64 * 1024
+
+var ErrAccessDenied = errors.New("access denied")
+
+func newUDPProxy(pconfig model.PortConfig, lb *balancer, hc *healthChecker, log zerolog.Logger,
+	access *connAccess,
+) *udpProxy {
+	return &udpProxy{
+		log:         log,
+		balancer:    lb,
+		healthCheck: hc,
+		sessions:    make(map[string]*udpSession),
+		denied:      make(map[string]time.Time),
+		idleTimeout: pconfig.GetIdleTimeout(),
+		dialTimeout: passthroughDialTimeout(pconfig),
+		access:      access,
+	}
+}
+
+// ServePacket method forwards datagrams until the packet connection is closed.
+func (t *udpProxy) ServePacket(pc net.PacketConn) error {
+	buf := make([]byte, udpBufferSize)
+
+	for {
+		n, addr, err := pc.ReadFrom(buf)
+		if err != nil {
+			return err
+		}
+
+		session, err := t.getSession(pc, addr)
+		switch {
+		case errors.Is(err, ErrAccessDenied):
+			continue
+		case err != nil:
+			t.log.Error().Err(err).Str("client", addr.String()).Msg("error forwarding datagram")
+			continue
+		}
+
+		_ = session.conn.SetReadDeadline(time.Now().Add(t.idleTimeout))
+		if _, err := session.conn.Write(buf[:n]); err != nil {
+			t.log.Error().Err(err).Str("client", addr.String()).Msg("error writing to upstream")
+			continue
+		}
+
+		t.mtx.Lock()
+		session.bytesIn += int64(n)
+		t.mtx.Unlock()
+		t.stats.bytesIn.Add(uint64(n)) //nolint:gosec
+	}
+}
+
+// Shutdown method closes all client sessions.
+func (t *udpProxy) Shutdown(_ context.Context) error {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	t.closed = true
+
+	var errs error
+	for _, session := range t.sessions {
+		errs = errors.Join(errs, session.conn.Close())
+	}
+
+	return errs
+}
+
+// getSession method returns the session of a client address, creating it if needed.
+// The access policies are checked once per session, the clients denied are
+// ignored during the idle timeout.
+func (t *udpProxy) getSession(pc net.PacketConn, addr net.Addr) (*udpSession, error) {
+	client := addr.String()
+
+	t.mtx.Lock()
+	session, ok := t.sessions[client]
+	deniedUntil, denied := t.denied[client]
+	t.mtx.Unlock()
+
+	switch {
+	case ok:
+		return session, nil
+	case denied && time.Now().Before(deniedUntil):
+		return nil, ErrAccessDenied
+	}
+
+	// the identity is resolved without blocking the other sessions
+	start := time.Now()
+	who := t.access.whois(client)
+
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	if !t.access.isAllowed(who, client) {
+		t.denied[client] = time.Now().Add(t.idleTimeout)
+		t.access.logConn(start, client, who, "", "", http.StatusForbidden, 0, 0)
+		return nil, ErrAccessDenied
+	}
+	delete(t.denied, client)
+
+	if t.closed {
+		return nil, net.ErrClosed
+	}
+
+	if session, ok := t.sessions[client]; ok {
+		return session, nil
+	}
+
+	u, conn, err := dialUpstream("udp", t.balancer, t.healthCheck, t.dialTimeout, t.log)
+	if err != nil {
+		t.access.logConn(start, client, who, "", "", http.StatusBadGateway, 0, 0)
+		return nil, err
+	}
+
+	session = &udpSession{
+		upstream: u,
+		conn:     conn,
+		who:      who,
+		start:    start,
+	}
+
+	t.sessions[client] = session
+	t.stats.active.Add(1)
+	t.stats.total.Add(1)
+	u.active.Add(1)
+
+	go t.reply(pc, addr, session)
+
+	return session, nil
+}
+
+// reply method copies the upstream responses back to the client until the session is idle.
+func (t *udpProxy) reply(pc net.PacketConn, addr net.Addr, session *udpSession) {
+	defer t.closeSession(addr, session)
+
+	buf := make([]byte, udpBufferSize)
+
+	for {
+		_ = session.conn.SetReadDeadline(time.Now().Add(t.idleTimeout))
+
+		n, err := session.conn.Read(buf)
+		if err != nil {
+			return
+		}
+
+		if _, err := pc.WriteTo(buf[:n], addr); err != nil {
+			t.log.Error().Err(err).Str("client", addr.String()).Msg("error writing to client")
+			return
+		}
+
+		t.mtx.Lock()
+		session.bytesOut += int64(n)
+		t.mtx.Unlock()
+		t.stats.bytesOut.Add(uint64(n)) //nolint:gosec
+	}
+}
+
+func (t *udpProxy) closeSession(addr net.Addr, session *udpSession) {
+	t.mtx.Lock()
+	delete(t.sessions, addr.String())
+	bytesIn, bytesOut := session.bytesIn, session.bytesOut
+	t.mtx.Unlock()
+
+	session.conn.Close()
+	session.upstream.active.Add(-1)
+	t.stats.active.Add(-1)
+
+	t.access.logConn(session.start, addr.String(), session.who, "", session.upstream.url.String(),
+		http.StatusOK, bytesIn, bytesOut)
+}
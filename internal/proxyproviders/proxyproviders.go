@@ -22,9 +22,11 @@ type (
 		Start(context.Context) error
 		Close() error
 		GetListener(port string) (net.Listener, error)
+		GetPacketListeners(port string) ([]net.PacketConn, error)
 		GetURL() string
 		GetAuthURL() string
 		WatchEvents() chan model.ProxyEvent
 		Whois(r *http.Request) model.Whois
+		WhoisAddr(ctx context.Context, remoteAddr string) model.Whois
 	}
 )
@@ -5,13 +5,17 @@ package tailscale
 
 import (
 	"context"
+	"crypto/tls"
+	"encoding/json"
 	"errors"
 	"net"
 	"net/http"
+	"net/netip"
 	"strconv"
 	"strings"
 	"sync"
 
+	"github.com/sudosu404/tailnet-lib/internal/metrics"
 	"github.com/sudosu404/tailnet-lib/internal/model"
 	"github.com/sudosu404/tailnet-lib/internal/proxyproviders"
 
@@ -42,6 +46,9 @@ var (
 	_ proxyproviders.ProxyInterface = (*Proxy)(nil)
 
 	ErrProxyPortNotFound = errors.New("proxy port not found")
+	ErrNoTailscaleIPs    = errors.New("no tailscale ips found")
+	ErrMagicDNSDisabled  = errors.New("MagicDNS must be enabled in the DNS page of the admin panel to use https")
+	ErrHTTPSDisabled     = errors.New("HTTPS must be enabled in the admin panel to use https")
 )
 
 // Start method implements proxyconfig.Proxy Start method.
@@ -90,20 +97,93 @@ func (p *Proxy) GetListener(port string) (net.Listener, error) {
 	}
 
 	network := portCfg.ProxyProtocol
-	if portCfg.ProxyProtocol == "http" || portCfg.ProxyProtocol == "https" {
+	if portCfg.ProxyProtocol == "http" || portCfg.ProxyProtocol == "https" || portCfg.ProxyProtocol == model.ProtocolTLS {
 		network = "tcp"
 	}
 	addr := ":" + strconv.Itoa(portCfg.ProxyPort)
 
 	if portCfg.Tailscale.Funnel {
-		return p.tsServer.ListenFunnel(network, addr)
+		// funnel listeners always terminate TLS, tls ports need the raw stream
+		if portCfg.ProxyProtocol == model.ProtocolTLS {
+			return nil, model.ErrFunnelTLS
+		}
+		return p.tsServer.ListenFunnel(network, addr, tsnet.FunnelTLSConfig(p.tlsConfig()))
 	}
 	if portCfg.ProxyProtocol == "https" {
-		return p.tsServer.ListenTLS(network, addr)
+		return p.listenTLS(network, addr)
 	}
 	return p.tsServer.Listen(network, addr)
 }
 
+// listenTLS method listens with the Tailscale certificate of the node.
+// It replaces tsnet ListenTLS to advertise HTTP/2, required by gRPC clients.
+func (p *Proxy) listenTLS(network, addr string) (net.Listener, error) {
+	status, err := p.tsServer.Up(p.ctx)
+	if err != nil {
+		return nil, err
+	}
+	if status.CurrentTailnet == nil || !status.CurrentTailnet.MagicDNSEnabled {
+		return nil, ErrMagicDNSDisabled
+	}
+	if len(status.CertDomains) == 0 {
+		return nil, ErrHTTPSDisabled
+	}
+
+	l, err := p.tsServer.Listen(network, addr)
+	if err != nil {
+		return nil, err
+	}
+
+	return tls.NewListener(l, p.tlsConfig()), nil
+}
+
+// tlsConfig method returns the TLS configuration of https ports.
+func (p *Proxy) tlsConfig() *tls.Config {
+	return &tls.Config{
+		GetCertificate: p.lc.GetCertificate,
+		NextProtos:     []string{"h2", "http/1.1"},
+	}
+}
+
+// GetPacketListeners method returns a packet listener for each Tailscale IP of the proxy.
+func (p *Proxy) GetPacketListeners(port string) ([]net.PacketConn, error) {
+	portCfg, ok := p.config.Ports[port]
+	if !ok {
+		return nil, ErrProxyPortNotFound
+	}
+
+	// packet listeners require the Tailscale IPs, wait until the node is running
+	if _, err := p.tsServer.Up(p.ctx); err != nil {
+		return nil, err
+	}
+
+	var pcs []net.PacketConn
+
+	ip4, ip6 := p.tsServer.TailscaleIPs()
+	for _, ip := range []netip.Addr{ip4, ip6} {
+		if !ip.IsValid() {
+			continue
+		}
+
+		addr := netip.AddrPortFrom(ip, uint16(portCfg.ProxyPort)) //nolint:gosec
+		pc, err := p.tsServer.ListenPacket(portCfg.ProxyProtocol, addr.String())
+		if err != nil {
+			for _, c := range pcs {
+				c.Close()
+			}
+			return nil, err
+		}
+
+		pcs = append(pcs, pc)
+	}
+
+	if len(pcs) == 0 {
+		return nil, ErrNoTailscaleIPs
+	}
+
+	return pcs, nil
+}
+
 func (p *Proxy) WatchEvents() chan model.ProxyEvent {
 	return p.events
 }
@@ -113,17 +193,45 @@ func (p *Proxy) GetAuthURL() string {
 }
 
 func (p *Proxy) Whois(r *http.Request) model.Whois {
-	who, err := p.lc.WhoIs(r.Context(), r.RemoteAddr)
+	return p.WhoisAddr(r.Context(), r.RemoteAddr)
+}
+
+// WhoisAddr method returns the identity of a tailnet address, like the remote address of a tcp connection.
+func (p *Proxy) WhoisAddr(ctx context.Context, remoteAddr string) model.Whois {
+	who, err := p.lc.WhoIs(ctx, remoteAddr)
 	if err != nil {
 		return model.Whois{}
 	}
 
-	return model.Whois{
+	whois := model.Whois{
 		DisplayName:   who.UserProfile.DisplayName,
 		Username:      who.UserProfile.LoginName,
 		ID:            who.UserProfile.ID.String(),
 		ProfilePicURL: who.UserProfile.ProfilePicURL,
 	}
+
+	if who.Node != nil {
+		whois.NodeName = who.Node.ComputedName
+		whois.NodeID = string(who.Node.StableID)
+		whois.Tags = who.Node.Tags
+
+		for _, addr := range who.Node.Addresses {
+			whois.Addresses = append(whois.Addresses, addr.Addr().String())
+		}
+	}
+
+	if len(who.CapMap) > 0 {
+		whois.Capabilities = make(map[string][]json.RawMessage, len(who.CapMap))
+		for capability, values := range who.CapMap {
+			raw := make([]json.RawMessage, 0, len(values))
+			for _, v := range values {
+				raw = append(raw, json.RawMessage(v))
+			}
+			whois.Capabilities[string(capability)] = raw
+		}
+	}
+
+	return whois
 }
 
 func (p *Proxy) watchStatus() {
@@ -154,6 +262,8 @@ func (p *Proxy) watchStatus() {
 			return
 		}
 
+		metrics.SetNodeState(p.config.Hostname, status.BackendState)
+
 		switch status.BackendState {
 		case "NeedsLogin":
 			if status.AuthURL != "" {
@@ -18,6 +18,33 @@ const (
 	LabelContainerAccessLog = LabelPrefix + "containeraccesslog"
 	LabelProxyProvider      = LabelPrefix + "proxyprovider"
 	LabelPort               = LabelPrefix + "port."
+	LabelRoute              = LabelPrefix + "route."
+	LabelHeader             = LabelPrefix + "header."
+	LabelRedirect           = LabelPrefix + "redirect."
+	// Access policy, comma separated values
+	LabelAccessPrefix      = LabelPrefix + "access."
+	LabelAccessAllowPrefix = LabelAccessPrefix + "allow."
+	LabelAccessDenyPrefix  = LabelAccessPrefix + "deny."
+	AccessRuleUsers        = "users"
+	AccessRuleUserIDs      = "userids"
+	AccessRuleTags         = "tags"
+	AccessRuleNodes        = "nodes"
+	AccessRuleCapabilities = "capabilities"
+	// Error pages, "tailnet.errorpage.<status code>" sets the message of a status code
+	LabelErrorPagePrefix = LabelPrefix + "errorpage."
+	LabelErrorPageBrand  = LabelErrorPagePrefix + "brand"
+	// Access log destination, overrides the access log of tailnet.yaml
+	LabelAccessLog           = LabelPrefix + "accesslog"
+	LabelAccessLogFormat     = LabelAccessLog + ".format"
+	LabelAccessLogOutput     = LabelAccessLog + ".output"
+	LabelAccessLogMaxSize    = LabelAccessLog + ".maxsize"
+	LabelAccessLogInterval   = LabelAccessLog + ".interval"
+	LabelAccessLogMaxBackups = LabelAccessLog + ".maxbackups"
+	LabelAccessLogMaxAge     = LabelAccessLog + ".maxage"
+	// Maintenance mode
+	LabelMaintenance           = LabelPrefix + "maintenance"
+	LabelMaintenanceMessage    = LabelMaintenance + ".message"
+	LabelMaintenanceRetryAfter = LabelMaintenance + ".retryafter"
 	// Tailscale
 	LabelEphemeral    = LabelPrefix + "ephemeral"
 	LabelRunWebClient = LabelPrefix + "runwebclient"
@@ -49,4 +76,103 @@ const (
 	// Port options
 	PortOptionNoTLSValidate   = "no_tlsvalidate"
 	PortOptionTailscaleFunnel = "tailscale_funnel"
+	PortOptionLoadBalancer    = "loadbalancer"
+	PortOptionHealthCheck     = "healthcheck"
+	PortOptionHealthInterval  = "healthcheck_interval"
+	PortOptionIdleTimeout     = "idle_timeout"
+	PortOptionTimeout         = "timeout"
+	PortOptionDialTimeout     = "dial_timeout"
+	PortOptionHeaderTimeout   = "response_header_timeout"
+	PortOptionReadTimeout     = "read_timeout"
+	PortOptionMaxBody         = "maxbody"
+	PortOptionTLSCA           = "tls_ca"
+	PortOptionTLSServerName   = "tls_servername"
+	PortOptionTLSCert         = "tls_cert"
+	PortOptionTLSKey          = "tls_key"
+	PortOptionProxyProtocol   = "send_proxy_protocol"
+	PortOptionCompress        = "compress"
+	PortOptionCompressMinSize = "compress_minsize"
+	PortOptionAllowUser       = "allow_user"
+	PortOptionAllowUserID     = "allow_userid"
+	PortOptionAllowTag        = "allow_tag"
+	PortOptionAllowNode       = "allow_node"
+	PortOptionDenyUser        = "deny_user"
+	PortOptionDenyUserID      = "deny_userid"
+	PortOptionDenyTag         = "deny_tag"
+	PortOptionDenyNode        = "deny_node"
+	PortOptionAllowCap        = "allow_cap"
+	PortOptionDenyCap         = "deny_cap"
+	PortOptionRateLimit       = "ratelimit"
+	PortOptionRateLimitUser   = "ratelimit_user"
+	PortOptionRateLimitNode   = "ratelimit_node"
+	PortOptionRateLimitIP     = "ratelimit_ip"
+	PortOptionMaxConcurrent   = "maxconcurrent"
+	PortOptionMaxConcUser     = "maxconcurrent_user"
+	PortOptionMaxConcNode     = "maxconcurrent_node"
+	PortOptionMaxConcIP       = "maxconcurrent_ip"
+	PortOptionIdentityToken   = "identity_token"
+	PortOptionIdentityHeader  = "identity_token_header"
+	PortOptionIdentityAud     = "identity_token_audience"
+	PortOptionIdentityTTL     = "identity_token_ttl"
+
+	// Redirect port options
+	PortOptionRedirectStatus   = "redirect_status"
+	PortOptionRedirectPreserve = "redirect_preserve"
+
+	// Load balancing port options
+	PortOptionTarget  = "target"
+	PortOptionWeights = "weights"
+
+	// Session affinity port options
+	PortOptionAffinity       = "affinity"
+	PortOptionAffinityCookie = "affinity_cookie"
+	PortOptionAffinityTTL    = "affinity_ttl"
+
+	// Mirror port options
+	PortOptionMirror        = "mirror"
+	PortOptionMirrorPercent = "mirror_percent"
+
+	// Canary port options
+	PortOptionCanary       = "canary"
+	PortOptionCanaryWeight = "canary_weight"
+	PortOptionCanaryHeader = "canary_header"
+	PortOptionCanaryCookie = "canary_cookie"
+	PortOptionCanaryUser   = "canary_user"
+	PortOptionCanaryTag    = "canary_tag"
+
+	// Port options separator between key and value
+	portOptionValueSeparator = "="
+	// Separator of the options of route and redirect rule labels, escaped with a backslash
+	optionsSeparator = ','
+	optionsEscape    = '\\'
+	// Separator of the values of the weights option
+	weightsSeparator = ":"
+
+	// Header labels "tailnet.header.<port index>.<direction>.<action>[.<header name>]"
+	HeaderDirectionRequest  = "request"
+	HeaderDirectionResponse = "response"
+	HeaderActionSet         = "set"
+	HeaderActionAdd         = "add"
+	HeaderActionRemove      = "remove"
+	// Identity header labels "tailnet.header.<port index>.identity.<option>"
+	HeaderIdentity              = "identity"
+	HeaderIdentityDisabled      = "disabled"
+	HeaderIdentityUsername      = "username"
+	HeaderIdentityDisplayName   = "displayname"
+	HeaderIdentityProfilePicURL = "profilepicurl"
+	HeaderIdentityCapabilities  = "capabilities"
+
+	// Route options
+	RouteOptionPath        = "path"
+	RouteOptionPathRegex   = "regex"
+	RouteOptionHost        = "host"
+	RouteOptionCapability  = "capability"
+	RouteOptionTarget      = "target"
+	RouteOptionStripPrefix = "strip_prefix"
+	RouteOptionRewrite     = "rewrite"
+
+	// Redirect rule options
+	RedirectOptionMatch  = "match"
+	RedirectOptionTarget = "target"
+	RedirectOptionStatus = "status"
 )
@@ -152,6 +152,10 @@ func (c *container) newProxyConfig() (*model.Config, error) {
 		pcfg.Dashboard.Icon = web.GuessIcon(c.image)
 	}
 
+	pcfg.AccessPolicy = c.getAccessPolicy()
+	pcfg.ErrorPages = c.getErrorPages()
+	pcfg.Maintenance = c.getMaintenance()
+	pcfg.AccessLog = c.getAccessLog()
 	pcfg.Ports = c.getPorts()
 
 	// add port from legacy labels if no port configured
@@ -183,33 +187,270 @@ func (c *container) getPorts() model.PortConfigList {
 		}
 
 		for _, v := range parts[1:] {
-			v = strings.TrimSpace(v)
-			switch v {
-			case PortOptionNoTLSValidate:
-				port.TLSValidate = false
-			case PortOptionTailscaleFunnel:
-				port.Tailscale.Funnel = true
-			}
+			c.setPortOption(&port, strings.TrimSpace(v))
 		}
 
-		if !port.IsRedirect {
-			port, err = c.generateTargetFromFirstTarget(port)
-			if err == nil {
-				ports[k] = port
-			} else {
-				c.log.Error().Err(err).Str("port", k).Msg("error generating target")
-			}
+		if err := port.Validate(); err != nil {
+			c.log.Error().Err(err).Str("port", k).Msg("invalid port configuration")
+			continue
+		}
+
+		if w := port.LoadBalancer.Weights; len(w) > 0 && len(w) != len(port.GetTargets()) {
+			c.log.Warn().Str("port", k).Msg("load balancer weights don't match the number of targets")
+		}
+
+		port.Routes = c.getRoutes(k)
+		port.Headers = c.getHeaders(k)
+
+		if port.IsRedirect {
+			port.Redirect.Rules = c.getRedirectRules(k)
+			ports[k] = port
+			continue
+		}
+
+		port, err = c.generateTargetFromFirstTarget(port)
+		if err == nil {
+			ports[k] = port
+		} else {
+			c.log.Error().Err(err).Str("port", k).Msg("error generating target")
 		}
 	}
 
 	return ports
 }
 
+// setPortOption method applies a port option in the form "<option>" or "<option>=<value>".
+func (c *container) setPortOption(port *model.PortConfig, option string) {
+	key, value, _ := strings.Cut(option, portOptionValueSeparator)
+
+	switch strings.TrimSpace(key) {
+	case PortOptionNoTLSValidate:
+		port.TLSValidate = false
+	case PortOptionTailscaleFunnel:
+		port.Tailscale.Funnel = true
+	case PortOptionLoadBalancer:
+		strategy, err := model.ParseLoadBalancerStrategy(value)
+		if err != nil {
+			c.log.Error().Err(err).Str("port", port.String()).Msg("error parsing port option")
+			return
+		}
+		port.LoadBalancer.Strategy = strategy
+	case PortOptionTarget:
+		target, err := c.getRouteTargetURL(strings.TrimSpace(value))
+		if err != nil {
+			c.log.Error().Err(err).Str("port", port.String()).Msg("error parsing port option")
+			return
+		}
+		port.AddTarget(target)
+	case PortOptionWeights:
+		c.setWeightsPortOption(port, value)
+	case PortOptionAffinity:
+		affinity, err := model.ParseSessionAffinityType(value)
+		if err != nil {
+			c.log.Error().Err(err).Str("port", port.String()).Msg("error parsing port option")
+			return
+		}
+		port.LoadBalancer.Affinity.Type = affinity
+	case PortOptionAffinityCookie:
+		port.LoadBalancer.Affinity.Cookie = strings.TrimSpace(value)
+	case PortOptionAffinityTTL:
+		c.setDurationPortOption(port, value, &port.LoadBalancer.Affinity.CookieTTL)
+	case PortOptionHealthCheck:
+		interval := port.HealthCheck.Interval
+		port.HealthCheck = model.NewHealthCheck(value)
+		port.HealthCheck.Interval = interval
+	case PortOptionHealthInterval:
+		c.setDurationPortOption(port, value, &port.HealthCheck.Interval)
+	case PortOptionIdleTimeout:
+		c.setDurationPortOption(port, value, &port.Timeouts.Idle)
+	case PortOptionTimeout:
+		c.setDurationPortOption(port, value, &port.Timeouts.Request)
+	case PortOptionDialTimeout:
+		c.setDurationPortOption(port, value, &port.Timeouts.Dial)
+	case PortOptionHeaderTimeout:
+		c.setDurationPortOption(port, value, &port.Timeouts.ResponseHeader)
+	case PortOptionReadTimeout:
+		c.setDurationPortOption(port, value, &port.Timeouts.Read)
+	case PortOptionMaxBody:
+		size, err := model.ParseByteSize(value)
+		if err != nil {
+			c.log.Error().Err(err).Str("port", port.String()).Msg("error parsing port option")
+			return
+		}
+		port.MaxBodySize = size
+	case PortOptionTLSCA:
+		port.UpstreamTLS.CAFile = strings.TrimSpace(value)
+	case PortOptionTLSServerName:
+		port.UpstreamTLS.ServerName = strings.TrimSpace(value)
+	case PortOptionTLSCert:
+		port.UpstreamTLS.CertFile = strings.TrimSpace(value)
+	case PortOptionTLSKey:
+		port.UpstreamTLS.KeyFile = strings.TrimSpace(value)
+	case PortOptionCompress:
+		algorithms, err := model.ParseCompressionAlgorithms(value)
+		if err != nil {
+			c.log.Error().Err(err).Str("port", port.String()).Msg("error parsing port option")
+			return
+		}
+		port.Compression.Enabled = true
+		port.Compression.Algorithms = algorithms
+	case PortOptionCompressMinSize:
+		size, err := model.ParseByteSize(value)
+		if err != nil {
+			c.log.Error().Err(err).Str("port", port.String()).Msg("error parsing port option")
+			return
+		}
+		port.Compression.MinSize = size
+	case PortOptionProxyProtocol:
+		switch version := strings.TrimSpace(value); version {
+		case model.ProxyProtocolV1, model.ProxyProtocolV2:
+			port.SendProxyProtocol = version
+		default:
+			c.log.Error().Str("port", port.String()).Str("value", version).Msg("invalid PROXY protocol version, expected v1 or v2")
+		}
+	case PortOptionAllowUser:
+		port.AccessPolicy.Allow.Users = append(port.AccessPolicy.Allow.Users, strings.TrimSpace(value))
+	case PortOptionAllowUserID:
+		port.AccessPolicy.Allow.UserIDs = append(port.AccessPolicy.Allow.UserIDs, strings.TrimSpace(value))
+	case PortOptionAllowTag:
+		port.AccessPolicy.Allow.Tags = append(port.AccessPolicy.Allow.Tags, strings.TrimSpace(value))
+	case PortOptionAllowNode:
+		port.AccessPolicy.Allow.Nodes = append(port.AccessPolicy.Allow.Nodes, strings.TrimSpace(value))
+	case PortOptionDenyUser:
+		port.AccessPolicy.Deny.Users = append(port.AccessPolicy.Deny.Users, strings.TrimSpace(value))
+	case PortOptionDenyUserID:
+		port.AccessPolicy.Deny.UserIDs = append(port.AccessPolicy.Deny.UserIDs, strings.TrimSpace(value))
+	case PortOptionDenyTag:
+		port.AccessPolicy.Deny.Tags = append(port.AccessPolicy.Deny.Tags, strings.TrimSpace(value))
+	case PortOptionDenyNode:
+		port.AccessPolicy.Deny.Nodes = append(port.AccessPolicy.Deny.Nodes, strings.TrimSpace(value))
+	case PortOptionAllowCap:
+		port.AccessPolicy.Allow.Capabilities = append(port.AccessPolicy.Allow.Capabilities, strings.TrimSpace(value))
+	case PortOptionDenyCap:
+		port.AccessPolicy.Deny.Capabilities = append(port.AccessPolicy.Deny.Capabilities, strings.TrimSpace(value))
+	case PortOptionRateLimit:
+		c.setRatePortOption(port, value, &port.RateLimits.Global)
+	case PortOptionRateLimitUser:
+		c.setRatePortOption(port, value, &port.RateLimits.PerUser)
+	case PortOptionRateLimitNode:
+		c.setRatePortOption(port, value, &port.RateLimits.PerNode)
+	case PortOptionRateLimitIP:
+		c.setRatePortOption(port, value, &port.RateLimits.PerClientIP)
+	case PortOptionMaxConcurrent:
+		c.setIntPortOption(port, value, &port.RateLimits.Global.MaxConcurrent)
+	case PortOptionMaxConcUser:
+		c.setIntPortOption(port, value, &port.RateLimits.PerUser.MaxConcurrent)
+	case PortOptionMaxConcNode:
+		c.setIntPortOption(port, value, &port.RateLimits.PerNode.MaxConcurrent)
+	case PortOptionMaxConcIP:
+		c.setIntPortOption(port, value, &port.RateLimits.PerClientIP.MaxConcurrent)
+	case PortOptionIdentityToken:
+		port.IdentityToken.Enabled = true
+	case PortOptionIdentityHeader:
+		port.IdentityToken.Header = strings.TrimSpace(value)
+	case PortOptionIdentityAud:
+		port.IdentityToken.Audience = strings.TrimSpace(value)
+	case PortOptionIdentityTTL:
+		c.setDurationPortOption(port, value, &port.IdentityToken.TTL)
+	case PortOptionRedirectStatus:
+		status, err := model.ParseRedirectStatus(value)
+		if err != nil {
+			c.log.Error().Err(err).Str("port", port.String()).Msg("error parsing port option")
+			return
+		}
+		port.Redirect.Status = status
+	case PortOptionRedirectPreserve:
+		port.Redirect.PreservePath = true
+	case PortOptionMirror:
+		c.setTargetPortOption(port, value, &port.Mirror.Target)
+	case PortOptionMirrorPercent:
+		percent, err := model.ParsePercent(value)
+		if err != nil {
+			c.log.Error().Err(err).Str("port", port.String()).Msg("error parsing port option")
+			return
+		}
+		port.Mirror.Percent = &percent
+	case PortOptionCanary:
+		c.setTargetPortOption(port, value, &port.Canary.Target)
+	case PortOptionCanaryWeight:
+		weight, err := model.ParsePercent(value)
+		if err != nil {
+			c.log.Error().Err(err).Str("port", port.String()).Msg("error parsing port option")
+			return
+		}
+		port.Canary.Weight = weight
+	case PortOptionCanaryHeader:
+		port.Canary.Header = strings.TrimSpace(value)
+	case PortOptionCanaryCookie:
+		port.Canary.Cookie = strings.TrimSpace(value)
+	case PortOptionCanaryUser:
+		port.Canary.Match.Users = append(port.Canary.Match.Users, strings.TrimSpace(value))
+	case PortOptionCanaryTag:
+		port.Canary.Match.Tags = append(port.Canary.Match.Tags, strings.TrimSpace(value))
+	}
+}
+
+// setRatePortOption method parses a rate port option value like "100/1m" into dst.
+func (c *container) setRatePortOption(port *model.PortConfig, value string, dst *model.RateLimit) {
+	if err := dst.ParseRate(value); err != nil {
+		c.log.Error().Err(err).Str("port", port.String()).Msg("error parsing port option")
+	}
+}
+
+// setIntPortOption method parses an integer port option value into dst.
+func (c *container) setIntPortOption(port *model.PortConfig, value string, dst *int) {
+	n, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil {
+		c.log.Error().Err(err).Str("port", port.String()).Msg("error parsing port option")
+		return
+	}
+	*dst = n
+}
+
+// setDurationPortOption method parses a duration port option value into dst.
+func (c *container) setDurationPortOption(port *model.PortConfig, value string, dst *time.Duration) {
+	d, err := time.ParseDuration(strings.TrimSpace(value))
+	if err != nil {
+		c.log.Error().Err(err).Str("port", port.String()).Msg("error parsing port option")
+		return
+	}
+	*dst = d
+}
+
+// setTargetPortOption method resolves a target port option value, an URL or a
+// container port like 8080/http, into dst.
+func (c *container) setTargetPortOption(port *model.PortConfig, value string, dst *string) {
+	target, err := c.getRouteTargetURL(strings.TrimSpace(value))
+	if err == nil && (target.Scheme == "" || target.Host == "") {
+		err = fmt.Errorf("%w: %s", ErrInvalidTargetURL, value)
+	}
+	if err != nil {
+		c.log.Error().Err(err).Str("port", port.String()).Msg("error parsing port option")
+		return
+	}
+	*dst = target.String()
+}
+
+// setWeightsPortOption method parses the weights of the port targets, like "3:1:1",
+// in the order of the targets: the port target first, then the target options.
+func (c *container) setWeightsPortOption(port *model.PortConfig, value string) {
+	weights := []int{}
+	for _, w := range strings.Split(value, weightsSeparator) {
+		n, err := strconv.Atoi(strings.TrimSpace(w))
+		if err != nil || n <= 0 {
+			c.log.Error().Err(err).Str("port", port.String()).Str("weights", value).Msg("invalid port target weights")
+			return
+		}
+		weights = append(weights, n)
+	}
+	port.LoadBalancer.Weights = weights
+}
+
 func (c *container) generateTargetFromFirstTarget(port model.PortConfig) (model.PortConfig, error) {
 	c.log.Trace().Msg("generateTargetFromFirstTarget")
 	defer c.log.Trace().Msg("End generateTargetFromFirstTarget")
 
-	// multiple targets not supported in this TargetProvider
+	// the port target is a container port, the targets added with options are already resolved
 	p := port.GetFirstTarget()
 
 	targetURL, err := c.getTargetURL(p)
@@ -223,6 +464,111 @@ func (c *container) generateTargetFromFirstTarget(port model.PortConfig) (model.
 	return port, nil
 }
 
+// getAccessPolicy method returns the proxy access policy from the
+// "tailnet.access.allow.*" and "tailnet.access.deny.*" labels.
+func (c *container) getAccessPolicy() model.AccessPolicy {
+	return model.AccessPolicy{
+		Allow: c.getAccessRule(LabelAccessAllowPrefix),
+		Deny:  c.getAccessRule(LabelAccessDenyPrefix),
+	}
+}
+
+// getErrorPages method returns the error pages of the proxy from the
+// "tailnet.errorpage.brand" and "tailnet.errorpage.<status code>" labels.
+func (c *container) getErrorPages() model.ErrorPages {
+	errorPages := model.ErrorPages{
+		Brand: c.getLabelString(LabelErrorPageBrand, ""),
+	}
+
+	for k, v := range c.labels {
+		code, ok := strings.CutPrefix(k, LabelErrorPagePrefix)
+		if !ok || k == LabelErrorPageBrand {
+			continue
+		}
+
+		statusCode, err := strconv.Atoi(code)
+		if err != nil {
+			c.log.Error().Err(err).Str("label", k).Msg("invalid error page label")
+			continue
+		}
+
+		if errorPages.Messages == nil {
+			errorPages.Messages = make(map[int]string)
+		}
+		errorPages.Messages[statusCode] = v
+	}
+
+	return errorPages
+}
+
+// getMaintenance method returns the initial maintenance mode of the proxy.
+func (c *container) getMaintenance() model.Maintenance {
+	m := model.Maintenance{
+		Enabled: c.getLabelBool(LabelMaintenance, false),
+		Message: c.getLabelString(LabelMaintenanceMessage, ""),
+	}
+
+	if v, ok := c.labels[LabelMaintenanceRetryAfter]; ok {
+		d, err := time.ParseDuration(strings.TrimSpace(v))
+		if err != nil {
+			c.log.Error().Err(err).Str("label", LabelMaintenanceRetryAfter).Msg("invalid maintenance label")
+			return m
+		}
+		m.RetryAfter = d
+	}
+
+	return m
+}
+
+// getAccessLog method returns the access log destination of the proxy.
+func (c *container) getAccessLog() model.AccessLog {
+	a := model.AccessLog{
+		Format: strings.ToLower(c.getLabelString(LabelAccessLogFormat, "")),
+		Output: c.getLabelString(LabelAccessLogOutput, ""),
+	}
+
+	if v, ok := c.labels[LabelAccessLogMaxSize]; ok {
+		size, err := model.ParseByteSize(v)
+		if err != nil {
+			c.log.Error().Err(err).Str("label", LabelAccessLogMaxSize).Msg("invalid access log label")
+		}
+		a.Rotation.MaxSize = size
+	}
+
+	if v, ok := c.labels[LabelAccessLogMaxBackups]; ok {
+		n, err := strconv.Atoi(strings.TrimSpace(v))
+		if err != nil {
+			c.log.Error().Err(err).Str("label", LabelAccessLogMaxBackups).Msg("invalid access log label")
+		}
+		a.Rotation.MaxBackups = n
+	}
+
+	for label, dst := range map[string]*time.Duration{
+		LabelAccessLogInterval: &a.Rotation.Interval,
+		LabelAccessLogMaxAge:   &a.Rotation.MaxAge,
+	} {
+		if v, ok := c.labels[label]; ok {
+			d, err := time.ParseDuration(strings.TrimSpace(v))
+			if err != nil {
+				c.log.Error().Err(err).Str("label", label).Msg("invalid access log label")
+			}
+			*dst = d
+		}
+	}
+
+	return a
+}
+
+func (c *container) getAccessRule(prefix string) model.AccessRule {
+	return model.AccessRule{
+		Users:        c.getLabelList(prefix + AccessRuleUsers),
+		UserIDs:      c.getLabelList(prefix + AccessRuleUserIDs),
+		Tags:         c.getLabelList(prefix + AccessRuleTags),
+		Nodes:        c.getLabelList(prefix + AccessRuleNodes),
+		Capabilities: c.getLabelList(prefix + AccessRuleCapabilities),
+	}
+}
+
 // getTailscaleConfig method returns the tailscale configuration.
 func (c *container) getTailscaleConfig() (*model.Tailscale, error) {
 	c.log.Trace().Msg("getTailscaleConfig")
@@ -268,8 +614,8 @@ func (c *container) getTargetURL(iPort *url.URL) (*url.URL, error) {
 		return url.Parse("http://127.0.0.1:" + internalPort)
 	}
 
-	// set autodetect
-	if c.autodetect {
+	// set autodetect, udp targets can't be detected with a tcp dial
+	if c.autodetect && iPort.Scheme != model.ProtocolUDP {
 		// repeat auto detect in case the container is not ready
 		for try := range autoDetectTries {
 			c.log.Info().Int("try", try).Msg("Trying to auto detect target URL")
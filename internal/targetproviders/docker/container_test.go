@@ -0,0 +1,49 @@
+// SPDX-FileCopyrightText: 2025 Hector @sudosu404 <hector@email.gnx>
+// SPDX-License-Identifier: AGPL3
+
+package docker
+
+import (
+	"testing"
+
+	"github.com/sudosu404/tailnet-lib/internal/model"
+
+	"github.com/rs/zerolog"
+)
+
+func TestSetTargetPortOptions(t *testing.T) {
+	c := &container{
+		log:                   zerolog.Nop(),
+		networkMode:           "host",
+		defaultBridgeAddress:  "172.17.0.1",
+		defaultTargetHostname: "172.17.0.1",
+	}
+
+	tests := []struct {
+		option     string
+		wantMirror string
+		wantCanary string
+	}{
+		{option: "mirror=http://app-v2:8080", wantMirror: "http://app-v2:8080"},
+		{option: "mirror=8081/http", wantMirror: "http://172.17.0.1:8081"},
+		{option: "mirror=8081", wantMirror: "http://172.17.0.1:8081"},
+		{option: "mirror=http://", wantMirror: ""},
+		{option: "canary=http://app-v2:8080", wantCanary: "http://app-v2:8080"},
+		{option: "canary=8081/https", wantCanary: "https://172.17.0.1:8081"},
+		{option: "canary=://bad", wantCanary: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.option, func(t *testing.T) {
+			port := model.PortConfig{}
+			c.setPortOption(&port, tt.option)
+
+			if port.Mirror.Target != tt.wantMirror {
+				t.Errorf("mirror: got %q, want %q", port.Mirror.Target, tt.wantMirror)
+			}
+			if port.Canary.Target != tt.wantCanary {
+				t.Errorf("canary: got %q, want %q", port.Canary.Target, tt.wantCanary)
+			}
+		})
+	}
+}
@@ -19,4 +19,5 @@ var (
 	ErrNoPortFoundInContainer              = errors.New("no port found in container")
 	ErrNoValidTargetFoundForInternalPorts  = errors.New("no valid target found for internal ports")
 	ErrNoValidTargetFoundForPublishedPorts = errors.New("no valid target found for exposed ports")
+	ErrInvalidTargetURL                    = errors.New("invalid target URL")
 )
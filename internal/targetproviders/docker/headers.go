@@ -0,0 +1,92 @@
+// SPDX-FileCopyrightText: 2025 Hector @sudosu404 <hector@email.gnx>
+// SPDX-License-Identifier: AGPL3
+
+package docker
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/sudosu404/tailnet-lib/internal/model"
+)
+
+// getHeaders method returns the header rules of a port from the labels
+// "tailnet.header.<port index>.<request|response>.<set|add|remove>[.<header name>]"
+// and "tailnet.header.<port index>.identity.<option>".
+func (c *container) getHeaders(portLabel string) model.Headers {
+	c.log.Trace().Msg("getHeaders")
+	defer c.log.Trace().Msg("End getHeaders")
+
+	prefix := LabelHeader + strings.TrimPrefix(portLabel, LabelPort) + "."
+
+	headers := model.Headers{}
+	for k, value := range c.labels {
+		key, ok := strings.CutPrefix(k, prefix)
+		if !ok {
+			continue
+		}
+
+		direction, rest, _ := strings.Cut(key, ".")
+		switch direction {
+		case HeaderDirectionRequest:
+			c.setHeaderRule(&headers.Request, k, rest, value)
+		case HeaderDirectionResponse:
+			c.setHeaderRule(&headers.Response, k, rest, value)
+		case HeaderIdentity:
+			c.setIdentityHeader(&headers.Identity, k, rest, value)
+		default:
+			c.log.Error().Str("label", k).Msg("unknown header label")
+		}
+	}
+
+	return headers
+}
+
+// setHeaderRule method adds a rule like "set.X-Header" or "remove".
+// Remove values are comma separated header names.
+func (c *container) setHeaderRule(rules *model.HeaderRules, label, key, value string) {
+	action, name, _ := strings.Cut(key, ".")
+
+	switch {
+	case action == HeaderActionRemove:
+		for _, name := range strings.Split(value, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				rules.Remove = append(rules.Remove, name)
+			}
+		}
+	case action == HeaderActionSet && name != "":
+		if rules.Set == nil {
+			rules.Set = make(map[string]string)
+		}
+		rules.Set[name] = value
+	case action == HeaderActionAdd && name != "":
+		if rules.Add == nil {
+			rules.Add = make(map[string]string)
+		}
+		rules.Add[name] = value
+	default:
+		c.log.Error().Str("label", label).Msg("invalid header label")
+	}
+}
+
+func (c *container) setIdentityHeader(identity *model.IdentityHeaders, label, option, value string) {
+	switch option {
+	case HeaderIdentityDisabled:
+		disabled, err := strconv.ParseBool(value)
+		if err != nil {
+			c.log.Error().Err(err).Str("label", label).Msg("invalid header label")
+			return
+		}
+		identity.Disabled = disabled
+	case HeaderIdentityUsername:
+		identity.Username = value
+	case HeaderIdentityDisplayName:
+		identity.DisplayName = value
+	case HeaderIdentityProfilePicURL:
+		identity.ProfilePicURL = value
+	case HeaderIdentityCapabilities:
+		identity.Capabilities = value
+	default:
+		c.log.Error().Str("label", label).Msg("invalid header label")
+	}
+}
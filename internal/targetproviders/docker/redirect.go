@@ -0,0 +1,71 @@
+// SPDX-FileCopyrightText: 2025 Hector @sudosu404 <hector@email.gnx>
+// SPDX-License-Identifier: AGPL3
+
+package docker
+
+import (
+	"errors"
+	"slices"
+	"strings"
+
+	"github.com/sudosu404/tailnet-lib/internal/model"
+)
+
+var ErrRedirectRuleIncomplete = errors.New("redirect rule without match or target")
+
+// getRedirectRules method returns the redirect rules of a port from the labels
+// "tailnet.redirect.<port index>.<rule name>", sorted by rule name.
+func (c *container) getRedirectRules(portLabel string) []model.RedirectRule {
+	prefix := LabelRedirect + strings.TrimPrefix(portLabel, LabelPort) + "."
+
+	names := []string{}
+	for k := range c.labels {
+		if strings.HasPrefix(k, prefix) {
+			names = append(names, strings.TrimPrefix(k, prefix))
+		}
+	}
+	slices.Sort(names)
+
+	rules := make([]model.RedirectRule, 0, len(names))
+	for _, name := range names {
+		rule, err := parseRedirectRule(c.labels[prefix+name])
+		if err != nil {
+			c.log.Error().Err(err).Str("redirect", prefix+name).Msg("error creating redirect rule")
+			continue
+		}
+
+		rules = append(rules, rule)
+	}
+
+	return rules
+}
+
+// parseRedirectRule function parses a redirect rule label value like
+// "match=^/old/(.*)$, target=/new/$1, status=308".
+func parseRedirectRule(value string) (model.RedirectRule, error) {
+	rule := model.RedirectRule{}
+
+	for _, option := range splitOptions(value) {
+		key, value, _ := strings.Cut(strings.TrimSpace(option), portOptionValueSeparator)
+		value = strings.TrimSpace(value)
+
+		switch strings.TrimSpace(key) {
+		case RedirectOptionMatch:
+			rule.Match = value
+		case RedirectOptionTarget:
+			rule.Target = value
+		case RedirectOptionStatus:
+			status, err := model.ParseRedirectStatus(value)
+			if err != nil {
+				return rule, err
+			}
+			rule.Status = status
+		}
+	}
+
+	if rule.Match == "" || rule.Target == "" {
+		return rule, ErrRedirectRuleIncomplete
+	}
+
+	return rule, nil
+}
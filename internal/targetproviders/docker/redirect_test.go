@@ -0,0 +1,48 @@
+// SPDX-FileCopyrightText: 2025 Hector @sudosu404 <hector@email.gnx>
+// SPDX-License-Identifier: AGPL3
+
+package docker
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/sudosu404/tailnet-lib/internal/model"
+)
+
+func TestParseRedirectRule(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    model.RedirectRule
+		wantErr error
+	}{
+		{
+			value: "match=^/old/(.*)$, target=/new/$1, status=308",
+			want:  model.RedirectRule{Match: "^/old/(.*)$", Target: "/new/$1", Status: 308},
+		},
+		{
+			value: `match=^/v[0-9]{1\,3}/(.*)$, target=https://example.com/$1`,
+			want:  model.RedirectRule{Match: "^/v[0-9]{1,3}/(.*)$", Target: "https://example.com/$1"},
+		},
+		{
+			value:   "match=^/old/(.*)$",
+			wantErr: ErrRedirectRuleIncomplete,
+		},
+		{
+			value:   "match=^/old/(.*)$, target=/new/$1, status=200",
+			wantErr: model.ErrInvalidRedirectStatus,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := parseRedirectRule(tt.value)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("got error %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
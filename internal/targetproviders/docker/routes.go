@@ -0,0 +1,103 @@
+// SPDX-FileCopyrightText: 2025 Hector @sudosu404 <hector@email.gnx>
+// SPDX-License-Identifier: AGPL3
+
+package docker
+
+import (
+	"errors"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/sudosu404/tailnet-lib/internal/model"
+)
+
+var ErrRouteWithoutTarget = errors.New("route without target")
+
+// getRoutes method returns the routes of a port from the labels
+// "tailnet.route.<port index>.<route name>", sorted by route name.
+func (c *container) getRoutes(portLabel string) []model.Route {
+	c.log.Trace().Msg("getRoutes")
+	defer c.log.Trace().Msg("End getRoutes")
+
+	prefix := LabelRoute + strings.TrimPrefix(portLabel, LabelPort) + "."
+
+	names := []string{}
+	for k := range c.labels {
+		if strings.HasPrefix(k, prefix) {
+			names = append(names, strings.TrimPrefix(k, prefix))
+		}
+	}
+	slices.Sort(names)
+
+	routes := make([]model.Route, 0, len(names))
+	for _, name := range names {
+		route, err := c.parseRoute(name, c.labels[prefix+name])
+		if err != nil {
+			c.log.Error().Err(err).Str("route", prefix+name).Msg("error creating route")
+			continue
+		}
+
+		routes = append(routes, route)
+	}
+
+	return routes
+}
+
+// parseRoute method parses a route label value like
+// "path=/grafana, strip_prefix, target=3000/http".
+func (c *container) parseRoute(name, value string) (model.Route, error) {
+	route := model.Route{Name: name}
+
+	for _, option := range splitOptions(value) {
+		key, value, _ := strings.Cut(strings.TrimSpace(option), portOptionValueSeparator)
+		value = strings.TrimSpace(value)
+
+		switch strings.TrimSpace(key) {
+		case RouteOptionPath:
+			route.PathPrefix = value
+		case RouteOptionPathRegex:
+			route.PathRegex = value
+		case RouteOptionHost:
+			route.Host = value
+		case RouteOptionCapability:
+			route.Capability = value
+		case RouteOptionStripPrefix:
+			route.StripPrefix = true
+		case RouteOptionRewrite:
+			route.Rewrite = value
+		case RouteOptionTarget:
+			target, err := c.getRouteTargetURL(value)
+			if err != nil {
+				return route, err
+			}
+			route.AddTarget(target)
+		}
+	}
+
+	if len(route.GetTargets()) == 0 {
+		return route, ErrRouteWithoutTarget
+	}
+
+	return route, nil
+}
+
+// getRouteTargetURL method returns the target of a route, either an URL
+// or a container port in the form "<container port>/<container protocol>".
+func (c *container) getRouteTargetURL(value string) (*url.URL, error) {
+	if strings.Contains(value, "://") {
+		return url.Parse(value)
+	}
+
+	targetPort, targetProtocol, ok := strings.Cut(value, "/")
+	if !ok {
+		targetProtocol = DefaultTargetScheme
+	}
+
+	internalURL, err := url.Parse(targetProtocol + "://0.0.0.0:" + targetPort)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.getTargetURL(internalURL)
+}
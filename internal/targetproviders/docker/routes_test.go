@@ -0,0 +1,61 @@
+// SPDX-FileCopyrightText: 2025 Hector @sudosu404 <hector@email.gnx>
+// SPDX-License-Identifier: AGPL3
+
+package docker
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+func TestSplitOptions(t *testing.T) {
+	tests := []struct {
+		value string
+		want  []string
+	}{
+		{value: "path=/a, strip_prefix", want: []string{"path=/a", " strip_prefix"}},
+		{value: `regex=^/v[0-9]{1\,3}/, target=3000`, want: []string{"regex=^/v[0-9]{1,3}/", " target=3000"}},
+		{value: `regex=^/\d+$`, want: []string{`regex=^/\d+$`}},
+		{value: `rewrite=/a\`, want: []string{`rewrite=/a\`}},
+		{value: "", want: []string{""}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			if got := splitOptions(tt.value); !slices.Equal(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseRoute(t *testing.T) {
+	c := &container{
+		log:                   zerolog.Nop(),
+		networkMode:           "host",
+		defaultBridgeAddress:  "172.17.0.1",
+		defaultTargetHostname: "172.17.0.1",
+	}
+
+	route, err := c.parseRoute("api", `regex=^/v[0-9]{1\,3}/(.*)$, rewrite=/$1, host=*.example.com, target=3000/http`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if route.PathRegex != "^/v[0-9]{1,3}/(.*)$" {
+		t.Errorf("got regex %q", route.PathRegex)
+	}
+	if route.Rewrite != "/$1" || route.Host != "*.example.com" {
+		t.Errorf("unexpected route %+v", route)
+	}
+	if targets := route.GetTargets(); len(targets) != 1 || targets[0].String() != "http://172.17.0.1:3000" {
+		t.Errorf("unexpected targets %v", targets)
+	}
+
+	if _, err := c.parseRoute("empty", "path=/a"); !errors.Is(err, ErrRouteWithoutTarget) {
+		t.Errorf("got error %v, want %v", err, ErrRouteWithoutTarget)
+	}
+}
@@ -37,6 +37,44 @@ func (c *container) getLabelString(label string, defaultValue string) string {
 	return value
 }
 
+// getLabelList method returns the comma separated values of a container label.
+func (c *container) getLabelList(label string) []string {
+	var values []string
+
+	for _, v := range strings.Split(c.labels[label], ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+
+	return values
+}
+
+// splitOptions function splits the options of a route or redirect rule label
+// on commas, "\," is kept as a comma in the value so regular expressions like
+// "^/v[0-9]{1\,3}/" can be used. Other backslashes are kept unchanged.
+func splitOptions(value string) []string {
+	var (
+		options []string
+		option  strings.Builder
+	)
+
+	for i := 0; i < len(value); i++ {
+		switch {
+		case value[i] == optionsEscape && i+1 < len(value) && value[i+1] == optionsSeparator:
+			option.WriteByte(optionsSeparator)
+			i++
+		case value[i] == optionsSeparator:
+			options = append(options, option.String())
+			option.Reset()
+		default:
+			option.WriteByte(value[i])
+		}
+	}
+
+	return append(options, option.String())
+}
+
 // getAuthKeyFromAuthFile method returns a auth key from a file.
 func (c *container) getAuthKeyFromAuthFile(authKey string) (string, error) {
 	authKeyFile, ok := c.labels[LabelAuthKeyFile]
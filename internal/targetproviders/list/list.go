@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"maps"
 	"net/url"
+	"path"
 	"reflect"
 	"sync"
 
@@ -37,17 +38,51 @@ type (
 	configProxyList map[string]proxyConfig
 
 	proxyConfig struct {
-		Dashboard     model.Dashboard `validate:"dive" yaml:"dashboard"`
-		Ports         map[string]port `yaml:"ports"`
-		ProxyProvider string          `yaml:"proxyProvider"`
-		Tailscale     model.Tailscale `yaml:"tailscale"`
+		Dashboard     model.Dashboard    `validate:"dive" yaml:"dashboard"`
+		Ports         map[string]port    `yaml:"ports"`
+		ProxyProvider string             `yaml:"proxyProvider"`
+		Tailscale     model.Tailscale    `yaml:"tailscale"`
+		AccessPolicy  model.AccessPolicy `validate:"dive" yaml:"accessPolicy,omitempty"`
+		ErrorPages    model.ErrorPages   `yaml:"errorPages,omitempty"`
+		Maintenance   model.Maintenance  `validate:"dive" yaml:"maintenance,omitempty"`
+		AccessLog     model.AccessLog    `validate:"dive" yaml:"accessLog,omitempty"`
 	}
 
 	port struct {
-		Targets     []string            `yaml:"targets,omitempty"`
-		Tailscale   model.TailscalePort `validate:"dive" yaml:"tailscale"`
-		IsRedirect  bool                `default:"false" validate:"boolean" yaml:"isRedirect,omitempty"`
-		TLSValidate bool                `validate:"boolean" default:"true" yaml:"tlsValidate"`
+		Targets      []string            `yaml:"targets,omitempty"`
+		Tailscale    model.TailscalePort `validate:"dive" yaml:"tailscale"`
+		IsRedirect   bool                `default:"false" validate:"boolean" yaml:"isRedirect,omitempty"`
+		TLSValidate  bool                `validate:"boolean" default:"true" yaml:"tlsValidate"`
+		LoadBalancer model.LoadBalancer  `validate:"dive" yaml:"loadBalancer,omitempty"`
+		HealthCheck  model.HealthCheck   `validate:"dive" yaml:"healthCheck,omitempty"`
+		Timeouts     model.PortTimeouts  `validate:"dive" yaml:"timeouts,omitempty"`
+		SNIRoutes    map[string]string   `yaml:"sniRoutes,omitempty"`
+		Routes       []route             `validate:"dive" yaml:"routes,omitempty"`
+		AccessPolicy model.AccessPolicy  `validate:"dive" yaml:"accessPolicy,omitempty"`
+		RateLimits   model.RateLimits    `validate:"dive" yaml:"rateLimits,omitempty"`
+		Headers      model.Headers       `validate:"dive" yaml:"headers,omitempty"`
+		// IdentityToken forwards a signed JWT with the client identity.
+		IdentityToken model.IdentityToken `validate:"dive" yaml:"identityToken,omitempty"`
+		// MaxBodySize limits the request body, like "10m" or "2g".
+		MaxBodySize model.ByteSize `yaml:"maxBodySize,omitempty"`
+		// UpstreamTLS configures the TLS connections to https targets.
+		UpstreamTLS model.UpstreamTLS `validate:"dive" yaml:"upstreamTLS,omitempty"`
+		// SendProxyProtocol sends a PROXY protocol header, "v1" or "v2", to the targets.
+		SendProxyProtocol string            `validate:"omitempty,oneof=v1 v2" yaml:"sendProxyProtocol,omitempty"`
+		Compression       model.Compression `validate:"dive" yaml:"compression,omitempty"`
+		// Redirect configures the status, path and rules of redirect ports.
+		Redirect model.Redirect `validate:"dive" yaml:"redirect,omitempty"`
+		// Files configures the ports with a file:// target.
+		Files model.FileServer `validate:"dive" yaml:"files,omitempty"`
+		// Mirror sends a copy of the requests to a shadow target.
+		Mirror model.Mirror `validate:"dive" yaml:"mirror,omitempty"`
+		// Canary sends part of the requests to a canary target.
+		Canary model.Canary `validate:"dive" yaml:"canary,omitempty"`
+	}
+
+	route struct {
+		model.Route `yaml:",inline"`
+		Targets     []string `yaml:"targets,omitempty"`
 	}
 )
 
@@ -179,6 +214,10 @@ func (c *Client) newProxyConfig(name string, p proxyConfig) (*model.Config, erro
 	pcfg.ProxyAccessLog = proxyAccessLog
 	pcfg.Ports = c.getPorts(p.Ports)
 	pcfg.Dashboard = p.Dashboard
+	pcfg.AccessPolicy = p.AccessPolicy
+	pcfg.ErrorPages = p.ErrorPages
+	pcfg.Maintenance = p.Maintenance
+	pcfg.AccessLog = p.AccessLog
 
 	c.addTarget(p, name)
 
@@ -221,16 +260,61 @@ func (c *Client) onFileChange(e fsnotify.Event) {
 			}
 			continue
 		}
-		// restart if the proxy configuration changed
+		// apply the changes of the proxy configuration
 		//
-		if !reflect.DeepEqual(c.configProxies[name], oldConfigProxies[name]) {
-			c.eventsChan <- targetproviders.TargetEvent{
-				ID:             name,
-				TargetProvider: c,
-				Action:         targetproviders.ActionRestartProxy,
-			}
+		for _, event := range c.getChangeEvents(name, oldConfigProxies[name], c.configProxies[name]) {
+			c.eventsChan <- event
+		}
+	}
+}
+
+// getChangeEvents method returns the events that apply the changes of a proxy configuration.
+// Only Tailscale changes restart the proxy and its tsnet node, the other changes
+// start, stop or restart the changed ports on the running node.
+func (c *Client) getChangeEvents(name string, oldProxy, newProxy proxyConfig) []targetproviders.TargetEvent {
+	if reflect.DeepEqual(oldProxy, newProxy) {
+		return nil
+	}
+
+	newEvent := func(action targetproviders.ActionType, port string) targetproviders.TargetEvent {
+		return targetproviders.TargetEvent{
+			ID:             name,
+			TargetProvider: c,
+			Action:         action,
+			Port:           port,
 		}
 	}
+
+	// Tailscale changes need a new node, and a proxy without ports never started its node
+	if oldProxy.ProxyProvider != newProxy.ProxyProvider || !reflect.DeepEqual(oldProxy.Tailscale, newProxy.Tailscale) ||
+		len(oldProxy.Ports) == 0 {
+		return []targetproviders.TargetEvent{newEvent(targetproviders.ActionRestartProxy, "")}
+	}
+
+	oldPorts, newPorts := oldProxy.Ports, newProxy.Ports
+
+	// proxy settings, like the access policy, are applied to all the ports
+	oldProxy.Ports, newProxy.Ports = nil, nil
+	proxyChanged := !reflect.DeepEqual(oldProxy, newProxy)
+
+	var events []targetproviders.TargetEvent
+	for key := range oldPorts {
+		if _, ok := newPorts[key]; !ok {
+			events = append(events, newEvent(targetproviders.ActionStopPrort, key))
+		}
+	}
+
+	for key, newPort := range newPorts {
+		oldPort, ok := oldPorts[key]
+		switch {
+		case !ok:
+			events = append(events, newEvent(targetproviders.ActionStartProt, key))
+		case proxyChanged || !reflect.DeepEqual(oldPort, newPort):
+			events = append(events, newEvent(targetproviders.ActionRestartPort, key))
+		}
+	}
+
+	return events
 }
 
 // addTarget method add a target the proxies map
@@ -251,10 +335,13 @@ func (c *Client) getPorts(l map[string]port) model.PortConfigList {
 		}
 
 		port.IsRedirect = v.IsRedirect
+		if port.IsRedirect {
+			port.Redirect = v.Redirect
+		}
 
 		for _, target := range v.Targets {
 			targetURL, err := url.Parse(target)
-			if err != nil || targetURL.Scheme == "" || targetURL.Host == "" {
+			if err != nil || !isValidTarget(targetURL) {
 				c.log.Error().Err(err).Str("port", k).Str("targetUrl", target).Msg("Invalid target URL")
 				// don't add this port and continue with other targets
 				continue
@@ -263,7 +350,34 @@ func (c *Client) getPorts(l map[string]port) model.PortConfigList {
 			port.AddTarget(targetURL)
 		}
 
-		if len(port.GetTargets()) == 0 {
+		for serverName, target := range v.SNIRoutes {
+			targetURL, err := url.Parse(target)
+			if err != nil || targetURL.Host == "" {
+				c.log.Error().Err(err).Str("port", k).Str("targetUrl", target).Msg("Invalid SNI route target URL")
+				continue
+			}
+
+			port.AddSNIRoute(serverName, targetURL)
+		}
+
+		for i, r := range v.Routes {
+			for _, target := range r.Targets {
+				targetURL, err := url.Parse(target)
+				if err != nil || targetURL.Scheme == "" || targetURL.Host == "" {
+					c.log.Error().Err(err).Str("port", k).Int("route", i).Str("targetUrl", target).Msg("Invalid route target URL")
+					continue
+				}
+
+				r.AddTarget(targetURL)
+			}
+
+			port.Routes = append(port.Routes, r.Route)
+		}
+
+		// https and rule redirects don't need a target
+		hasRedirect := port.IsRedirect && (port.Redirect.HTTPS || len(port.Redirect.Rules) > 0)
+
+		if len(port.GetTargets()) == 0 && len(port.SNIRoutes) == 0 && len(port.Routes) == 0 && !hasRedirect {
 			c.log.Error().Str("port", k).Msg("no targets found for port")
 			continue
 		}
@@ -271,7 +385,59 @@ func (c *Client) getPorts(l map[string]port) model.PortConfigList {
 		port.TLSValidate = v.TLSValidate
 		port.Tailscale = v.Tailscale
 
+		strategy, err := model.ParseLoadBalancerStrategy(string(v.LoadBalancer.Strategy))
+		if err != nil {
+			c.log.Error().Err(err).Str("port", k).Msg("using default load balancer strategy")
+		}
+		port.LoadBalancer = v.LoadBalancer
+		port.LoadBalancer.Strategy = strategy
+
+		if v.LoadBalancer.Affinity.IsEnabled() {
+			affinity, err := model.ParseSessionAffinityType(string(v.LoadBalancer.Affinity.Type))
+			if err != nil {
+				c.log.Error().Err(err).Str("port", k).Msg("session affinity disabled")
+			}
+			port.LoadBalancer.Affinity.Type = affinity
+		}
+
+		if len(v.LoadBalancer.Weights) > 0 && len(v.LoadBalancer.Weights) != len(port.GetTargets()) {
+			c.log.Warn().Str("port", k).Msg("load balancer weights don't match the number of targets")
+		}
+
+		port.AccessPolicy = v.AccessPolicy
+		port.RateLimits = v.RateLimits
+		port.Headers = v.Headers
+		port.IdentityToken = v.IdentityToken
+		port.Timeouts = v.Timeouts
+		port.MaxBodySize = v.MaxBodySize
+		port.UpstreamTLS = v.UpstreamTLS
+		port.SendProxyProtocol = v.SendProxyProtocol
+		port.Compression = v.Compression
+		port.Files = v.Files
+		port.Mirror = v.Mirror
+		port.Canary = v.Canary
+		port.HealthCheck = v.HealthCheck
+		if port.HealthCheck.Type == "" && port.HealthCheck.Path != "" {
+			port.HealthCheck.Type = model.HealthCheckHTTP
+		}
+
+		// validated once all the options are set
+		if err := port.Validate(); err != nil {
+			c.log.Error().Err(err).Str("port", k).Msg("invalid port configuration")
+			continue
+		}
+
 		ports[k] = port
 	}
 	return ports
 }
+
+// isValidTarget function returns true for URLs with a scheme and a host,
+// or file:// URLs with an absolute path.
+func isValidTarget(target *url.URL) bool {
+	if target.Scheme == model.SchemeFile {
+		return target.Host == "" && path.IsAbs(target.Path)
+	}
+
+	return target.Scheme != "" && target.Host != ""
+}
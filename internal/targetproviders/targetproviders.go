@@ -29,6 +29,16 @@ const (
 	ActionRestartPort
 )
 
+var actionTypeStrings = []string{
+	"",
+	"start_proxy",
+	"stop_proxy",
+	"restart_proxy",
+	"start_port",
+	"stop_port",
+	"restart_port",
+}
+
 type (
 	ActionType int
 
@@ -36,5 +46,14 @@ type (
 		TargetProvider TargetProvider
 		ID             string
 		Action         ActionType
+		// Port is the port name of the port actions, like "443/https".
+		Port string
 	}
 )
+
+func (a ActionType) String() string {
+	if int(a) < 0 || int(a) >= len(actionTypeStrings) {
+		return "unknown"
+	}
+	return actionTypeStrings[a]
+}
@@ -16,8 +16,13 @@ import (
 //go:generate templ generate
 
 func RenderTempl(w http.ResponseWriter, r *http.Request, cmp templ.Component) error {
+	return RenderTemplWithStatus(w, r, cmp, http.StatusOK)
+}
+
+// RenderTemplWithStatus function renders a component with a custom status code.
+func RenderTemplWithStatus(w http.ResponseWriter, r *http.Request, cmp templ.Component, statusCode int) error {
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
-	w.WriteHeader(http.StatusOK)
+	w.WriteHeader(statusCode)
 
 	err := cmp.Render(r.Context(), w)
 	if err != nil {